@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/photoprism/photoprism/internal/brains"
 	"github.com/photoprism/photoprism/internal/config"
+	"github.com/photoprism/photoprism/internal/entity"
+	"github.com/photoprism/photoprism/internal/photoprism"
 	"github.com/photoprism/photoprism/internal/query"
 	"github.com/photoprism/photoprism/pkg/fs"
 	"github.com/urfave/cli/v2"
@@ -47,10 +50,46 @@ var BrainsCommand = &cli.Command{
 			Usage:  "Download BRAINS neural network models",
 			Action: brainsDownloadAction,
 		},
+		{
+			Name:   "index",
+			Usage:  "Index faces, labels and perceptual hashes for originals",
+			Action: brainsIndexAction,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "path",
+					Aliases: []string{"p"},
+					Usage:   "path to specific photo or directory",
+				},
+				&cli.BoolFlag{
+					Name:  "rescan",
+					Usage: "reprocess files that were already indexed",
+				},
+				&cli.BoolFlag{
+					Name:  "faces-only",
+					Usage: "only detect and embed faces",
+				},
+				&cli.BoolFlag{
+					Name:  "labels-only",
+					Usage: "only run the object label classifier",
+				},
+				&cli.IntFlag{
+					Name:  "workers",
+					Usage: "maximum number of worker goroutines",
+				},
+			},
+		},
 		{
 			Name:   "status",
 			Usage:  "Show BRAINS status information",
 			Action: brainsStatusAction,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "watch",
+					Aliases: []string{"w"},
+					Usage:   "keep running and report job progress as it changes",
+					Value:   false,
+				},
+			},
 		},
 	},
 }
@@ -83,39 +122,87 @@ func brainsStatusAction(ctx *cli.Context) error {
 	fmt.Println("BRAINS Status")
 	fmt.Println("-------------")
 	fmt.Printf("BRAINS Enabled: %t\n", conf.BrainsEnabled())
-	
+	fmt.Printf("Device: %s\n", conf.BrainsDevice())
+
 	capabilities := conf.BrainsCapabilities()
 	fmt.Println("Capabilities:")
 	fmt.Printf("  Object Detection: %t\n", capabilities["object_detection"])
 	fmt.Printf("  Aesthetic Scoring: %t\n", capabilities["aesthetic_scoring"])
 	fmt.Printf("  Scene Understanding: %t\n", capabilities["scene_understanding"])
+	fmt.Printf("RAW/HEIC Processing: %t\n", conf.BrainsProcessRaw())
+	fmt.Printf("Video Samples: %d\n", conf.BrainsVideoSamples())
 
 	// Check model availability
 	fmt.Printf("Models Downloaded: %t\n", conf.BrainsModelsDownloaded())
+	fmt.Printf("Models Verified: %t\n", brains.ModelsVerified(filepath.Join(conf.AssetsPath(), "brains")))
 	fmt.Printf("Models Path: %s\n", conf.BrainsPath())
 
+	if ctx.Bool("watch") {
+		return watchBrainsJobs()
+	}
+
+	return nil
+}
+
+// brainsWatchInterval controls how often `brains status --watch` polls for
+// job progress.
+const brainsWatchInterval = 2 * time.Second
+
+// watchBrainsJobs polls queued, running, and paused BRAINS jobs and prints
+// their progress until interrupted. It polls the database rather than
+// subscribing to in-process progress events, since the CLI runs in its own
+// process, separate from whatever server or scheduler is doing the work.
+func watchBrainsJobs() error {
+	fmt.Println()
+	fmt.Println("Watching BRAINS jobs, press Ctrl+C to stop...")
+
+	ticker := time.NewTicker(brainsWatchInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		running, err := entity.FindBrainsJobsByStatus(entity.JobRunning)
+		if err != nil {
+			return fmt.Errorf("failed to load running jobs: %v", err)
+		}
+
+		queued, err := entity.FindBrainsJobsByStatus(entity.JobQueued)
+		if err != nil {
+			return fmt.Errorf("failed to load queued jobs: %v", err)
+		}
+
+		paused, err := entity.FindBrainsJobsByStatus(entity.JobPaused)
+		if err != nil {
+			return fmt.Errorf("failed to load paused jobs: %v", err)
+		}
+
+		fmt.Printf("\n%s  running=%d queued=%d paused=%d\n",
+			time.Now().Format("15:04:05"), len(running), len(queued), len(paused))
+
+		for _, job := range running {
+			fmt.Printf("  %s  %d/%d photos\n", job.ID, job.Cursor, job.Total)
+		}
+	}
+
 	return nil
 }
 
-// brainsDownloadAction downloads BRAINS models.
+// brainsDownloadAction downloads BRAINS models for every registered
+// processor, resuming interrupted transfers and verifying checksums rather
+// than relying on a fixed download script.
 func brainsDownloadAction(ctx *cli.Context) error {
 	conf, err := InitConfig(ctx)
-	
+
 	if err != nil {
 		return err
 	}
 
 	fmt.Println("Downloading BRAINS neural network models...")
-	
-	scriptPath := filepath.Join(conf.AppPath(), "scripts", "download-brains.sh")
-	if !fs.FileExists(scriptPath) {
-		return fmt.Errorf("download script not found: %s", scriptPath)
-	}
-	
-	if err := fs.Shell("bash", scriptPath); err != nil {
+
+	modelPath := filepath.Join(conf.AssetsPath(), "brains")
+	if err := brains.DownloadModels(conf, modelPath); err != nil {
 		return fmt.Errorf("failed to download BRAINS models: %v", err)
 	}
-	
+
 	fmt.Println("BRAINS models successfully downloaded!")
 	return nil
 }
@@ -177,8 +264,8 @@ func brainsAnalyzeAction(ctx *cli.Context) error {
 		}
 		
 		for _, photo := range photos {
-			if filename := photo.FileName(); filename != "" {
-				files = append(files, filepath.Join(conf.OriginalsPath(), filename))
+			if filePath, err := b.SelectInputPath(photo); err == nil {
+				files = append(files, filePath)
 			}
 		}
 	}
@@ -199,7 +286,68 @@ func brainsAnalyzeAction(ctx *cli.Context) error {
 	
 	fmt.Printf("Successfully analyzed %d files\n", len(results.Files))
 	fmt.Printf("Results saved to %s\n", outputFile)
-	
+
+	return nil
+}
+
+// brainsIndexAction runs the offline face, label and perceptual-hash
+// indexing pipeline over originals, keyed by file path rather than the
+// database-backed photo index so it can cover files that haven't been
+// imported yet.
+func brainsIndexAction(ctx *cli.Context) error {
+	conf, err := InitConfig(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if !conf.BrainsEnabled() {
+		return fmt.Errorf("BRAINS is not enabled in configuration")
+	}
+
+	path := ctx.String("path")
+	if path == "" {
+		path = conf.OriginalsPath()
+	}
+
+	var files []string
+
+	if fs.FileExists(path) {
+		files = []string{path}
+	} else if fs.DirectoryExists(path) {
+		foundFiles, err := fs.FindFiles(path, fs.ImageJPEG)
+		if err != nil {
+			return fmt.Errorf("error finding files: %v", err)
+		}
+		files = foundFiles
+	} else {
+		return fmt.Errorf("path not found: %s", path)
+	}
+
+	fmt.Printf("Found %d files to index\n", len(files))
+
+	b := brains.New(conf)
+	if err := b.Init(); err != nil {
+		return fmt.Errorf("failed to initialize BRAINS: %v", err)
+	}
+
+	maxWorkers := ctx.Int("workers")
+	if maxWorkers <= 0 {
+		maxWorkers = conf.IndexWorkers()
+	}
+
+	workers := photoprism.CalculateOptimalWorkers(maxWorkers)
+
+	opt := brains.IndexPipelineOptions{
+		FacesOnly:  ctx.Bool("faces-only"),
+		LabelsOnly: ctx.Bool("labels-only"),
+		Rescan:     ctx.Bool("rescan"),
+	}
+
+	pipeline := brains.NewIndexPipeline(b, workers, opt)
+	result := pipeline.Run(files)
+
+	fmt.Printf("Indexed %d files, %d failed\n", result.Processed, result.Failed)
+
 	return nil
 }
-```
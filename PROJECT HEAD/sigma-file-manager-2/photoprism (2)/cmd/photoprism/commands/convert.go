@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/photoprism/photoprism/internal/photoprism"
+	"github.com/urfave/cli/v2"
+)
+
+// ConvertCommand registers the 'convert' CLI command.
+var ConvertCommand = &cli.Command{
+	Name:   "convert",
+	Usage:  "Converts files to other formats",
+	Flags:  append(convertFlags, convertFormatFlag),
+	Action: convertAction,
+}
+
+// ThumbsCommand registers the 'thumbs' CLI command.
+var ThumbsCommand = &cli.Command{
+	Name:   "thumbs",
+	Usage:  "Creates thumbnails for all originals",
+	Flags:  convertFlags,
+	Action: thumbsAction,
+}
+
+// convertFlags are shared by ConvertCommand and ThumbsCommand, so a run
+// interrupted partway through a huge archive can resume exactly where it
+// left off, or be split into reproducible ranges for CI comparison.
+var convertFlags = []cli.Flag{
+	&cli.BoolFlag{
+		Name:    "force",
+		Aliases: []string{"f"},
+		Usage:   "re-process files that were already converted",
+	},
+	&cli.StringFlag{
+		Name:  "start",
+		Usage: "resume a sorted run from this path onward",
+	},
+	&cli.IntFlag{
+		Name:  "limit",
+		Usage: "maximum number of files to process in this run",
+	},
+	&cli.BoolFlag{
+		Name:  "resume",
+		Usage: "skip files already recorded as done in a previous run",
+	},
+}
+
+// convertFormatFlag selects ConvertCommand's output format. It's kept
+// separate from convertFlags since thumbs generation has no equivalent
+// choice.
+var convertFormatFlag = &cli.StringFlag{
+	Name:  "format",
+	Usage: "output format: jpeg, webp or avif",
+	Value: photoprism.FormatJpeg,
+}
+
+// convertOptions builds the shared photoprism.ConvertOptions from the CLI
+// flags convert and thumbs have in common.
+func convertOptions(ctx *cli.Context) photoprism.ConvertOptions {
+	return photoprism.ConvertOptions{
+		Force:     ctx.Bool("force"),
+		Resume:    ctx.Bool("resume"),
+		StartPath: ctx.String("start"),
+		Limit:     ctx.Int("limit"),
+		Format:    ctx.String("format"),
+	}
+}
+
+// convertAction converts all originals to the configured target formats.
+func convertAction(ctx *cli.Context) error {
+	conf, err := InitConfig(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	convert := photoprism.NewConvert(conf)
+
+	fmt.Println("Converting originals...")
+
+	if err := convert.Start(conf.OriginalsPath(), nil, convertOptions(ctx)); err != nil {
+		return fmt.Errorf("convert failed: %v", err)
+	}
+
+	fmt.Println("Done.")
+
+	return nil
+}
+
+// thumbsAction creates thumbnails for all originals.
+func thumbsAction(ctx *cli.Context) error {
+	conf, err := InitConfig(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	thumbs := photoprism.NewThumbs(conf)
+
+	fmt.Println("Creating thumbnails...")
+
+	if err := thumbs.Start(conf.OriginalsPath(), nil, convertOptions(ctx)); err != nil {
+		return fmt.Errorf("thumbs failed: %v", err)
+	}
+
+	fmt.Println("Done.")
+
+	return nil
+}
@@ -1,11 +1,20 @@
 package api
 
 import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"gopkg.in/yaml.v2"
+
 	"github.com/photoprism/photoprism/internal/acl"
 	"github.com/photoprism/photoprism/internal/brains"
 	"github.com/photoprism/photoprism/internal/entity"
@@ -15,16 +24,29 @@ import (
 	"github.com/photoprism/photoprism/internal/i18n"
 	"github.com/photoprism/photoprism/internal/photoprism"
 	"github.com/photoprism/photoprism/internal/query"
+	"github.com/photoprism/photoprism/internal/search"
 	"github.com/photoprism/photoprism/pkg/fs"
+	"github.com/photoprism/photoprism/pkg/rnd"
 	"github.com/photoprism/photoprism/pkg/txt"
 )
 
+// brainsUpgrader upgrades /api/v1/brains/events requests to a WebSocket
+// connection. Origin checking is left to the surrounding auth middleware,
+// consistent with how the rest of the BRAINS API authorizes each request.
+var brainsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
 // RegisterBrainsRoutes registers all BRAINS API routes.
 func RegisterBrainsRoutes(router *gin.RouterGroup) {
 	GetBrainsStatus(router)
 	DownloadBrainsModels(router)
 	UpdateBrainsModels(router)
 	GetBrainsModelVersions(router)
+	RollbackBrainsModel(router)
+	GetBrainsWorkers(router)
 	SetBrainsCapabilities(router)
 	GetBrainsCapabilities(router)
 	AnalyzeBrainsPhotos(router)
@@ -32,12 +54,31 @@ func RegisterBrainsRoutes(router *gin.RouterGroup) {
 	GetPhotoAesthetic(router)
 	GetPhotoScene(router)
 	GetPhotoObjects(router)
+	GetSimilarPhotos(router)
 	ClearBrainsCache(router)
 	// Add new automation routes
 	StartBrainsScheduler(router)
 	StopBrainsScheduler(router)
 	GetBrainsSchedulerStatus(router)
 	CurateBrainsCollections(router)
+	// Add job progress routes
+	GetBrainsJobs(router)
+	BrainsEvents(router)
+	SubmitBrainsJob(router)
+	GetBrainsJobEvents(router)
+	StreamBrainsJobEvents(router)
+	// Add persistent pipeline routes
+	EnqueueBrainsFiles(router)
+	StartBrainsPipeline(router)
+	StopBrainsPipeline(router)
+	GetBrainsPipelineStats(router)
+	// Add faceted discovery route
+	SearchBrainsPhotos(router)
+	// Add curated collection download route
+	DownloadBrainsCuration(router)
+	// Add curated collection share routes
+	GetBrainsCurations(router)
+	DeleteBrainsCurationLink(router)
 }
 
 // GetBrainsStatus returns the status of BRAINS.
@@ -81,7 +122,12 @@ func GetBrainsStatus(router *gin.RouterGroup) {
 	})
 }
 
-// DownloadBrainsModels initiates a download of BRAINS models.
+// DownloadBrainsModels initiates a download of BRAINS models via the signed
+// manifest ModelRegistry already uses for updates, replacing the old
+// download-brains.sh shell-out with a native, checksum-verified, resumable
+// downloader. A model with no installed version migrates the same way an
+// out-of-date one does, so first-time setup and later updates share one
+// code path.
 func DownloadBrainsModels(router *gin.RouterGroup) {
 	router.POST("/api/v1/brains/models/download", func(c *gin.Context) {
 		s := Auth(c, acl.ResourceConfig, acl.ActionUpdate)
@@ -98,25 +144,24 @@ func DownloadBrainsModels(router *gin.RouterGroup) {
 			return
 		}
 
+		b := brains.New(conf)
+		if err := b.Init(); err != nil {
+			AbortSaveFailed(c, i18n.ErrUnexpected)
+			return
+		}
+
 		// Create a task for model download
 		task := get.TaskManager()
 		downloadTask := entity.NewTask(entity.TaskBrainsDownload, "download brains models", entity.TaskPriorityHigh)
 		task.Start(downloadTask, func(task *entity.Task) {
 			task.SetStatus(entity.TaskStatusRunning)
-			scriptPath := filepath.Join(conf.AppPath(), "scripts", "download-brains.sh")
-			
-			if !fs.FileExists(scriptPath) {
-				task.SetStatus(entity.TaskStatusError)
-				task.SetErrorMessage("download script not found")
-				return
-			}
-			
-			if err := fs.Shell("bash", scriptPath); err != nil {
+
+			if err := b.UpdateModels(task); err != nil {
 				task.SetStatus(entity.TaskStatusError)
 				task.SetErrorMessage(err.Error())
 				return
 			}
-			
+
 			task.SetStatus(entity.TaskStatusCompleted)
 		})
 
@@ -151,14 +196,15 @@ func UpdateBrainsModels(router *gin.RouterGroup) {
 			return
 		}
 
-		// Check for updates
-		hasUpdates, err := b.CheckForModelUpdates()
+		// Check for updates and describe them, so an admin can review the
+		// from/to/size diff before confirming the download.
+		diffs, err := b.DescribeModelUpdates()
 		if err != nil {
 			AbortSaveFailed(c, i18n.ErrUnexpected)
 			return
 		}
 
-		if !hasUpdates {
+		if len(diffs) == 0 {
 			c.JSON(http.StatusOK, gin.H{
 				"message": "models already up-to-date",
 				"updated": false,
@@ -166,18 +212,29 @@ func UpdateBrainsModels(router *gin.RouterGroup) {
 			return
 		}
 
+		// Without confirm=true, report the diff without downloading
+		// anything, so the caller can show it to an admin first.
+		if c.Query("confirm") != "true" {
+			c.JSON(http.StatusOK, gin.H{
+				"message": "model updates available",
+				"updated": false,
+				"updates": diffs,
+			})
+			return
+		}
+
 		// Create a task for model update
 		task := get.TaskManager()
 		updateTask := entity.NewTask(entity.TaskBrainsUpdate, "update brains models", entity.TaskPriorityHigh)
 		task.Start(updateTask, func(task *entity.Task) {
 			task.SetStatus(entity.TaskStatusRunning)
-			
-			if err := b.UpdateModels(); err != nil {
+
+			if err := b.UpdateModels(task); err != nil {
 				task.SetStatus(entity.TaskStatusError)
 				task.SetErrorMessage(err.Error())
 				return
 			}
-			
+
 			task.SetStatus(entity.TaskStatusCompleted)
 		})
 
@@ -185,6 +242,7 @@ func UpdateBrainsModels(router *gin.RouterGroup) {
 			"message": i18n.Msg(i18n.MsgTaskStarted),
 			"task_id": updateTask.ID,
 			"updated": true,
+			"updates": diffs,
 		})
 	})
 }
@@ -222,6 +280,74 @@ func GetBrainsModelVersions(router *gin.RouterGroup) {
 	})
 }
 
+// RollbackBrainsModel reverts a model type to its last successfully
+// migrated version, for operators recovering from a bad UpdateBrainsModels
+// run.
+func RollbackBrainsModel(router *gin.RouterGroup) {
+	router.POST("/api/v1/brains/models/:name/rollback", func(c *gin.Context) {
+		s := Auth(c, acl.ResourceConfig, acl.ActionUpdate)
+
+		if !s.Admin() {
+			AbortForbidden(c)
+			return
+		}
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		name := c.Param("name")
+
+		b := brains.New(conf)
+		if err := b.Init(); err != nil {
+			AbortSaveFailed(c, i18n.ErrUnexpected)
+			return
+		}
+
+		if err := b.RollbackModel(name); err != nil {
+			AbortSaveFailed(c, i18n.ErrUnexpected)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": fmt.Sprintf("rolled back %s", name),
+		})
+	})
+}
+
+// GetBrainsWorkers lists the remote workers BrainsWorkerMode has dispatched
+// to, their last heartbeat and in-flight job count, for monitoring a worker
+// cluster handling AnalyzeBrainsPhotos jobs. The list is empty when running
+// in local mode.
+func GetBrainsWorkers(router *gin.RouterGroup) {
+	router.GET("/api/v1/brains/workers", func(c *gin.Context) {
+		s := Auth(c, acl.ResourceConfig, acl.ActionRead)
+
+		if !s.Admin() {
+			AbortForbidden(c)
+			return
+		}
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		b := brains.New(conf)
+		if err := b.Init(); err != nil {
+			AbortSaveFailed(c, i18n.ErrUnexpected)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"workers": b.Workers()})
+	})
+}
+
 // SetBrainsCapabilities updates BRAINS capability settings.
 func SetBrainsCapabilities(router *gin.RouterGroup) {
 	router.POST("/api/v1/brains/capabilities", func(c *gin.Context) {
@@ -355,24 +481,25 @@ func AnalyzeBrainsPhotos(router *gin.RouterGroup) {
 				// Create a list of file paths
 				var files []string
 				for _, photo := range photos {
-					if filename := photo.FileName(); filename != "" {
-						files = append(files, filepath.Join(conf.OriginalsPath(), filename))
+					if filePath, err := b.SelectInputPath(photo); err == nil {
+						files = append(files, filePath)
 					}
-					
+
 					// Update progress
 					progress := float64(len(files)) / float64(len(photos)) * 100
 					task.SetProgressPercent(int(progress))
 				}
 				
-				// Process files
-				_, err = b.ProcessFiles(files)
-				
+				// Process files, fanned out across a remote worker cluster
+				// when BrainsWorkerMode is configured for one.
+				_, err = b.Dispatcher().Dispatch(files, task.SetProgressPercent)
+
 				if err != nil {
 					task.SetStatus(entity.TaskStatusError)
 					task.SetErrorMessage(err.Error())
 					return
 				}
-				
+
 				task.SetStatus(entity.TaskStatusCompleted)
 			})
 
@@ -413,8 +540,7 @@ func AnalyzeBrainsPhotos(router *gin.RouterGroup) {
 					continue
 				}
 				
-				if filename := photo.FileName(); filename != "" {
-					filePath := filepath.Join(conf.OriginalsPath(), filename)
+				if filePath, err := b.SelectInputPath(photo); err == nil {
 					files = append(files, filePath)
 				}
 				
@@ -423,15 +549,16 @@ func AnalyzeBrainsPhotos(router *gin.RouterGroup) {
 				task.SetProgressPercent(int(progress))
 			}
 			
-			// Process files
-			_, err := b.ProcessFiles(files)
-			
+			// Process files, fanned out across a remote worker cluster when
+			// BrainsWorkerMode is configured for one.
+			_, err := b.Dispatcher().Dispatch(files, task.SetProgressPercent)
+
 			if err != nil {
 				task.SetStatus(entity.TaskStatusError)
 				task.SetErrorMessage(err.Error())
 				return
 			}
-			
+
 			task.SetStatus(entity.TaskStatusCompleted)
 		})
 
@@ -850,9 +977,10 @@ func CurateBrainsCollections(router *gin.RouterGroup) {
 
 		// Parse request
 		var req struct {
-			Refresh bool `json:"refresh"` // Whether to force refresh existing collections
+			Refresh bool                    `json:"refresh"` // Whether to force refresh existing collections
+			Share   *form.BrainsCurateShare `json:"share"`   // Optional: publish every curated album as a share link
 		}
-		
+
 		if err := c.BindJSON(&req); err != nil {
 			// Set default values if parsing fails
 			req.Refresh = false
@@ -863,7 +991,7 @@ func CurateBrainsCollections(router *gin.RouterGroup) {
 		curateTask := entity.NewTask(entity.TaskBrainsCurate, "curate collections with brains", entity.TaskPriorityNormal)
 		task.Start(curateTask, func(task *entity.Task) {
 			task.SetStatus(entity.TaskStatusRunning)
-			
+
 			// Initialize BRAINS
 			b := brains.New(conf)
 			if err := b.Init(); err != nil {
@@ -871,18 +999,37 @@ func CurateBrainsCollections(router *gin.RouterGroup) {
 				task.SetErrorMessage(err.Error())
 				return
 			}
-			
+
 			// Run auto-curation
 			albums, err := b.AutoCurateCollections()
-			
+
 			if err != nil {
 				task.SetStatus(entity.TaskStatusError)
 				task.SetErrorMessage(err.Error())
 				return
 			}
-			
+
 			task.SetStatus(entity.TaskStatusCompleted)
-			task.SetNotes(fmt.Sprintf("Curated %d collections", len(albums)))
+
+			if req.Share == nil {
+				task.SetNotes(fmt.Sprintf("Curated %d collections", len(albums)))
+				return
+			}
+
+			var tokens []string
+
+			for _, album := range albums {
+				link, linkErr := publishCurationLink(album.AlbumUID, *req.Share)
+				if linkErr != nil {
+					log.Warnf("brains: failed to publish share link for %s: %v", album.AlbumUID, linkErr)
+					continue
+				}
+
+				tokens = append(tokens, link.ShareToken)
+			}
+
+			task.SetNotes(fmt.Sprintf("Curated %d collections, published %d share links: %s",
+				len(albums), len(tokens), strings.Join(tokens, ", ")))
 		})
 
 		c.JSON(http.StatusOK, gin.H{
@@ -891,3 +1038,881 @@ func CurateBrainsCollections(router *gin.RouterGroup) {
 		})
 	})
 }
+
+// GetBrainsJobs returns active BRAINS tasks as well as queued, running, and
+// paused BRAINS jobs, so the UI can render progress without depending on a
+// scheduler instance that doesn't outlive a single request.
+func GetBrainsJobs(router *gin.RouterGroup) {
+	router.GET("/api/v1/brains/jobs", func(c *gin.Context) {
+		s := Auth(c, acl.ResourceConfig, acl.ActionRead)
+
+		if !s.Admin() {
+			AbortForbidden(c)
+			return
+		}
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		task := get.TaskManager()
+
+		queued, _ := entity.FindBrainsJobsByStatus(entity.JobQueued)
+		running, _ := entity.FindBrainsJobsByStatus(entity.JobRunning)
+		paused, _ := entity.FindBrainsJobsByStatus(entity.JobPaused)
+
+		c.JSON(http.StatusOK, gin.H{
+			"active_tasks": task.FindPending("brains"),
+			"jobs": gin.H{
+				"queued":  queued,
+				"running": running,
+				"paused":  paused,
+			},
+		})
+	})
+}
+
+// BrainsEvents relays brains.progress, brains.completed, and brains.failed
+// events to the frontend over a WebSocket connection, so long analysis runs
+// can show live progress instead of polling GetBrainsJobs.
+func BrainsEvents(router *gin.RouterGroup) {
+	router.GET("/api/v1/brains/events", func(c *gin.Context) {
+		s := Auth(c, acl.ResourceConfig, acl.ActionRead)
+
+		if !s.Admin() {
+			AbortForbidden(c)
+			return
+		}
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		conn, err := brainsUpgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			log.Warnf("brains: failed to upgrade websocket connection: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		events, unsubscribe := brains.Subscribe()
+		defer unsubscribe()
+
+		for ev := range events {
+			if err := conn.WriteJSON(ev); err != nil {
+				return
+			}
+		}
+	})
+}
+
+// SubmitBrainsJob resolves the given photo IDs to input files and submits
+// them as a BRAINS job, returning its ID immediately so the caller can poll
+// GetBrainsJobEvents or subscribe to StreamBrainsJobEvents for per-file
+// progress, which AnalyzeBrainsPhotos' task does not record.
+func SubmitBrainsJob(router *gin.RouterGroup) {
+	router.POST("/api/v1/brains/jobs", func(c *gin.Context) {
+		s := Auth(c, acl.ResourcePhotos, acl.ActionUpdate)
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		var req AnalyzePhotoRequest
+		if err := c.BindJSON(&req); err != nil {
+			AbortBadRequest(c, txt.UcFirst(err.Error()))
+			return
+		}
+
+		if req.PhotoID != "" && len(req.PhotoIDs) == 0 {
+			req.PhotoIDs = []string{req.PhotoID}
+		}
+
+		if len(req.PhotoIDs) == 0 {
+			AbortBadRequest(c, i18n.ErrInvalidID)
+			return
+		}
+
+		b := brains.New(conf)
+		if err := b.Init(); err != nil {
+			AbortSaveFailed(c, i18n.ErrUnexpected)
+			return
+		}
+
+		q := query.New(conf.Db())
+
+		var files []string
+		for _, uid := range req.PhotoIDs {
+			photo, err := q.PhotoByUID(uid)
+			if err != nil {
+				continue
+			}
+
+			if filePath, err := b.SelectInputPath(photo); err == nil {
+				files = append(files, filePath)
+			}
+		}
+
+		if len(files) == 0 {
+			AbortBadRequest(c, i18n.ErrInvalidID)
+			return
+		}
+
+		jobID, err := b.SubmitFiles(files)
+		if err != nil {
+			AbortSaveFailed(c, i18n.ErrUnexpected)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"job_id": jobID,
+		})
+	})
+}
+
+// GetBrainsJobEvents returns the per-file events recorded for a job, so the
+// UI can show which files succeeded, failed, or were skipped and retry just
+// the failed ones. Pass ?since=<RFC3339 timestamp> to fetch only events
+// newer than the last one already seen.
+func GetBrainsJobEvents(router *gin.RouterGroup) {
+	router.GET("/api/v1/brains/jobs/:uid/events", func(c *gin.Context) {
+		s := Auth(c, acl.ResourceConfig, acl.ActionRead)
+
+		if !s.Admin() {
+			AbortForbidden(c)
+			return
+		}
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		jobID := c.Param("uid")
+
+		since := time.Time{}
+		if s := c.Query("since"); s != "" {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				since = t
+			}
+		}
+
+		b := brains.New(conf)
+
+		events, err := b.GetJobEvents(jobID, since)
+		if err != nil {
+			AbortEntityNotFound(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, events)
+	})
+}
+
+// brainsJobEventPollInterval is how often StreamBrainsJobEvents re-checks
+// GetBrainsJobEvents for new rows between pushes to the client.
+const brainsJobEventPollInterval = 500 * time.Millisecond
+
+// StreamBrainsJobEvents streams a job's events to the UI over
+// server-sent-events as they're recorded, so a "reprocess" view can show
+// live per-file progress without opening a WebSocket. The stream ends once
+// the job reaches a terminal status or the client disconnects.
+func StreamBrainsJobEvents(router *gin.RouterGroup) {
+	router.GET("/api/v1/brains/jobs/:uid/stream", func(c *gin.Context) {
+		s := Auth(c, acl.ResourceConfig, acl.ActionRead)
+
+		if !s.Admin() {
+			AbortForbidden(c)
+			return
+		}
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		jobID := c.Param("uid")
+		b := brains.New(conf)
+		since := time.Time{}
+
+		c.Stream(func(w io.Writer) bool {
+			events, err := b.GetJobEvents(jobID, since)
+			if err != nil {
+				return false
+			}
+
+			for _, ev := range events {
+				c.SSEvent("job_event", ev)
+				since = ev.CreatedAt
+			}
+
+			job, err := entity.FindBrainsJob(jobID)
+			if err != nil {
+				return false
+			}
+
+			if job.Status == entity.JobCompleted || job.Status == entity.JobFailed {
+				return false
+			}
+
+			time.Sleep(brainsJobEventPollInterval)
+
+			return true
+		})
+	})
+}
+
+// EnqueueBrainsFiles adds files to the persistent BRAINS pipeline queue and
+// returns immediately, without waiting for them to be processed.
+func EnqueueBrainsFiles(router *gin.RouterGroup) {
+	router.POST("/api/v1/brains/pipeline/enqueue", func(c *gin.Context) {
+		s := Auth(c, acl.ResourcePhotos, acl.ActionUpdate)
+
+		if !s.Admin() {
+			AbortForbidden(c)
+			return
+		}
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		var req AnalyzePhotoRequest
+		if err := c.BindJSON(&req); err != nil {
+			AbortBadRequest(c, txt.UcFirst(err.Error()))
+			return
+		}
+
+		if req.PhotoID != "" && len(req.PhotoIDs) == 0 {
+			req.PhotoIDs = []string{req.PhotoID}
+		}
+
+		if len(req.PhotoIDs) == 0 {
+			AbortBadRequest(c, i18n.ErrInvalidID)
+			return
+		}
+
+		b := brains.New(conf)
+		if err := b.Init(); err != nil {
+			AbortSaveFailed(c, i18n.ErrUnexpected)
+			return
+		}
+
+		q := query.New(conf.Db())
+
+		var files []string
+		for _, uid := range req.PhotoIDs {
+			photo, err := q.PhotoByUID(uid)
+			if err != nil {
+				continue
+			}
+
+			if filePath, err := b.SelectInputPath(photo); err == nil {
+				files = append(files, filePath)
+			}
+		}
+
+		if len(files) == 0 {
+			AbortBadRequest(c, i18n.ErrInvalidID)
+			return
+		}
+
+		if err := b.Pipeline().Enqueue(files); err != nil {
+			log.Errorf("brains: failed to enqueue files: %v", err)
+			AbortSaveFailed(c, i18n.ErrUnexpected)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "files enqueued",
+			"count":   len(files),
+		})
+	})
+}
+
+// StartBrainsPipeline starts the persistent pipeline's workers.
+func StartBrainsPipeline(router *gin.RouterGroup) {
+	router.POST("/api/v1/brains/pipeline/start", func(c *gin.Context) {
+		s := Auth(c, acl.ResourceConfig, acl.ActionUpdate)
+
+		if !s.Admin() {
+			AbortForbidden(c)
+			return
+		}
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		var req struct {
+			Workers int `json:"workers"`
+		}
+
+		_ = c.BindJSON(&req)
+
+		b := brains.New(conf)
+		b.Pipeline().Start(req.Workers)
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "BRAINS pipeline started",
+		})
+	})
+}
+
+// StopBrainsPipeline stops the persistent pipeline's workers, letting any
+// in-flight lease finish before they exit.
+func StopBrainsPipeline(router *gin.RouterGroup) {
+	router.POST("/api/v1/brains/pipeline/stop", func(c *gin.Context) {
+		s := Auth(c, acl.ResourceConfig, acl.ActionUpdate)
+
+		if !s.Admin() {
+			AbortForbidden(c)
+			return
+		}
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		b := brains.New(conf)
+		b.Pipeline().Stop()
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "BRAINS pipeline stopped",
+		})
+	})
+}
+
+// GetBrainsPipelineStats returns the pipeline's current backlog depth and
+// cumulative throughput.
+func GetBrainsPipelineStats(router *gin.RouterGroup) {
+	router.GET("/api/v1/brains/pipeline/stats", func(c *gin.Context) {
+		s := Auth(c, acl.ResourceConfig, acl.ActionRead)
+
+		if !s.Admin() {
+			AbortForbidden(c)
+			return
+		}
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		b := brains.New(conf)
+
+		stats, err := b.Pipeline().Stats()
+		if err != nil {
+			log.Errorf("brains: failed to read pipeline stats: %v", err)
+			AbortSaveFailed(c, i18n.ErrUnexpected)
+			return
+		}
+
+		c.JSON(http.StatusOK, stats)
+	})
+}
+
+// GetSimilarPhotos returns photos visually similar to :uid, ranked by BRAINS
+// embedding distance, optionally narrowed by scene_type, indoor_outdoor,
+// time_of_day, and a processed_after/processed_before date range.
+func GetSimilarPhotos(router *gin.RouterGroup) {
+	router.GET("/api/v1/brains/:uid/similar", func(c *gin.Context) {
+		s := Auth(c, acl.ResourcePhotos, acl.ActionRead)
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		uid := c.Param("uid")
+		if uid == "" {
+			AbortBadRequest(c, i18n.ErrInvalidID)
+			return
+		}
+
+		photo := entity.FindPhoto(uid, conf.Db())
+		if photo == nil {
+			AbortEntityNotFound(c)
+			return
+		}
+
+		count := txt.Int(c.Query("count"))
+		if count <= 0 {
+			count = 12
+		}
+
+		var filters []brains.Filter
+
+		if sceneType := c.Query("scene_type"); sceneType != "" {
+			filters = append(filters, brains.FilterSceneType(sceneType))
+		}
+
+		if indoorOutdoor := c.Query("indoor_outdoor"); indoorOutdoor != "" {
+			filters = append(filters, brains.FilterIndoorOutdoor(indoorOutdoor))
+		}
+
+		if timeOfDay := c.Query("time_of_day"); timeOfDay != "" {
+			filters = append(filters, brains.FilterTimeOfDay(timeOfDay))
+		}
+
+		from, _ := time.Parse(time.RFC3339, c.Query("processed_after"))
+		to, _ := time.Parse(time.RFC3339, c.Query("processed_before"))
+
+		if !from.IsZero() || !to.IsZero() {
+			filters = append(filters, brains.FilterProcessedRange(from, to))
+		}
+
+		b := brains.New(conf)
+
+		results, err := b.SimilarPhotos(photo.ID, count, filters...)
+		if err != nil {
+			c.JSON(http.StatusOK, gin.H{
+				"error":     "no embedding available for this photo",
+				"available": false,
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"available": true,
+			"results":   results,
+			"count":     len(results),
+		})
+	})
+}
+
+// SearchBrainsPhotos turns the per-photo BRAINS result endpoints into a
+// discovery surface: it filters the photo library by any combination of
+// aesthetic score, scene, indoor/outdoor, time of day, weather, objects,
+// emotions, and keywords, following the same query-string-bound,
+// X-Count/X-Limit/X-Offset-headered convention as the rest of the search
+// API.
+func SearchBrainsPhotos(router *gin.RouterGroup) {
+	router.GET("/api/v1/brains/photos", func(c *gin.Context) {
+		s := Auth(c, acl.ResourcePhotos, acl.ActionSearch)
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		var f form.SearchBrains
+		if err := c.ShouldBindQuery(&f); err != nil {
+			AbortBadRequest(c, txt.UcFirst(err.Error()))
+			return
+		}
+
+		results, count, err := search.NewBrainsSearch(query.New(conf.Db())).Find(f)
+		if err != nil {
+			AbortBadRequest(c, txt.UcFirst(err.Error()))
+			return
+		}
+
+		limit := f.Count
+		if limit <= 0 {
+			limit = 100
+		}
+
+		c.Header("X-Count", fmt.Sprintf("%d", count))
+		c.Header("X-Limit", fmt.Sprintf("%d", limit))
+		c.Header("X-Offset", fmt.Sprintf("%d", f.Offset))
+
+		c.JSON(http.StatusOK, results)
+	})
+}
+
+// brainsCurationDefaultLimit and brainsCurationMaxLimit bound how many
+// photos DownloadBrainsCuration will pack into one archive.
+const (
+	brainsCurationDefaultLimit = 50
+	brainsCurationMaxLimit     = 500
+)
+
+// brainsCurationCandidate is one photo considered for inclusion in a
+// curation download, resolved directly from brains_results so the archive
+// can be ordered and filtered by aesthetic score without loading every
+// photo's BRAINS result individually.
+type brainsCurationCandidate struct {
+	PhotoUID       string
+	PhotoID        string
+	AestheticScore float32
+	SceneType      string
+}
+
+// brainsCurationManifestEntry documents why one file was included in a
+// curation download, written alongside the photos as manifest.yaml so
+// downstream tools don't have to re-run BRAINS analysis to find out.
+type brainsCurationManifestEntry struct {
+	PhotoUID       string                         `yaml:"PhotoUID"`
+	FileName       string                         `yaml:"FileName"`
+	AestheticScore float32                        `yaml:"AestheticScore"`
+	SceneType      string                         `yaml:"SceneType,omitempty"`
+	Objects        []brainsCurationManifestObject `yaml:"Objects,omitempty"`
+}
+
+// brainsCurationManifestObject is one detected-object entry in a
+// brainsCurationManifestEntry.
+type brainsCurationManifestObject struct {
+	Label      string  `yaml:"Label"`
+	Confidence float32 `yaml:"Confidence"`
+}
+
+// brainsRawExtensions are file extensions DownloadBrainsCuration treats as
+// camera raw, gated by DownloadSettings.MediaRaw.
+var brainsRawExtensions = map[string]bool{
+	".raw": true, ".cr2": true, ".cr3": true, ".nef": true, ".arw": true,
+	".dng": true, ".orf": true, ".rw2": true,
+}
+
+// DownloadBrainsCuration streams a zip archive of BRAINS-curated photos,
+// either the highest-aesthetic photos from album_uid (the default), or
+// every photo at or above min_score across the whole library when min_score
+// is given. A manifest.yaml sidecar inside the archive records each file's
+// BRAINS scores and detected objects, so a recipient can tell why a photo
+// was picked without re-running analysis. This mirrors the album
+// zip-download flow, but sources its file list from brains_results instead
+// of a fixed album membership list. The endpoint only ever serves original
+// files, so it's rejected outright unless DownloadSettings.Originals or an
+// explicit originals= query param allows that.
+func DownloadBrainsCuration(router *gin.RouterGroup) {
+	router.GET("/api/v1/brains/curate/:album_uid/download", func(c *gin.Context) {
+		s := Auth(c, acl.ResourcePhotos, acl.ActionDownload)
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		settings := conf.Settings().Download()
+
+		if settings.Disabled {
+			AbortForbidden(c)
+			return
+		}
+
+		albumUID := c.Param("album_uid")
+
+		limit := txt.Int(c.Query("limit"))
+		if limit <= 0 {
+			limit = brainsCurationDefaultLimit
+		} else if limit > brainsCurationMaxLimit {
+			limit = brainsCurationMaxLimit
+		}
+
+		minScore := txt.Float(c.Query("min_score"))
+		sceneType := strings.TrimSpace(c.Query("scene"))
+
+		useOriginals := settings.Originals
+		if raw := c.Query("originals"); raw != "" {
+			useOriginals = raw == "true" || raw == "1"
+		}
+
+		if !useOriginals {
+			// This endpoint only ever adds original files to the archive;
+			// it doesn't generate converted/preview media. Reject the
+			// request up front instead of silently returning a zip that
+			// contains a manifest but no photos.
+			AbortForbidden(c)
+			return
+		}
+
+		db := conf.Db()
+
+		q := db.Table("brains_results").
+			Select("photos.photo_uid, brains_results.photo_id, brains_results.aesthetic_score, brains_results.scene_type").
+			Joins("JOIN photos ON photos.id = brains_results.photo_id")
+
+		if minScore > 0 {
+			q = q.Where("brains_results.aesthetic_score >= ?", minScore)
+		} else {
+			q = q.Joins("JOIN photos_albums ON photos_albums.photo_uid = photos.photo_uid").
+				Where("photos_albums.album_uid = ?", albumUID)
+		}
+
+		if sceneType != "" {
+			q = q.Where("LOWER(brains_results.scene_type) = ?", txt.Lower(sceneType))
+		}
+
+		var candidates []brainsCurationCandidate
+		if err := q.Order("brains_results.aesthetic_score DESC").Limit(limit).Scan(&candidates).Error; err != nil {
+			AbortSaveFailed(c, i18n.ErrUnexpected)
+			return
+		}
+
+		if len(candidates) == 0 {
+			AbortEntityNotFound(c)
+			return
+		}
+
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="brains-curation-%s.zip"`, albumUID))
+
+		zw := zip.NewWriter(c.Writer)
+
+		var manifest []brainsCurationManifestEntry
+
+		for _, candidate := range candidates {
+			photo := entity.FindPhoto(candidate.PhotoUID, db)
+			if photo == nil {
+				continue
+			}
+
+			file, err := photo.PrimaryFile()
+			if err != nil {
+				continue
+			}
+
+			fileName := file.FileName()
+			if fileName == "" {
+				continue
+			}
+
+			ext := strings.ToLower(filepath.Ext(fileName))
+			if brainsRawExtensions[ext] && !settings.MediaRaw {
+				continue
+			}
+
+			sourcePath := filepath.Join(conf.OriginalsPath(), fileName)
+			if !fs.FileExists(sourcePath) {
+				continue
+			}
+
+			if err := addFileToZip(zw, sourcePath, fileName); err != nil {
+				log.Warnf("brains: failed to add %s to curation archive: %v", fileName, err)
+				continue
+			}
+
+			objects, err := entity.FindBrainsObjects(candidate.PhotoID)
+			if err != nil {
+				objects = nil
+			}
+
+			entry := brainsCurationManifestEntry{
+				PhotoUID:       candidate.PhotoUID,
+				FileName:       fileName,
+				AestheticScore: candidate.AestheticScore,
+				SceneType:      candidate.SceneType,
+			}
+
+			for _, o := range objects {
+				entry.Objects = append(entry.Objects, brainsCurationManifestObject{Label: o.Label, Confidence: o.Confidence})
+			}
+
+			manifest = append(manifest, entry)
+
+			if settings.MediaSidecar {
+				sidecarPath := strings.TrimSuffix(sourcePath, filepath.Ext(sourcePath)) + ".xmp"
+				sidecarName := strings.TrimSuffix(fileName, filepath.Ext(fileName)) + ".xmp"
+
+				if fs.FileExists(sidecarPath) {
+					if err := addFileToZip(zw, sidecarPath, sidecarName); err != nil {
+						log.Warnf("brains: failed to add sidecar for %s to curation archive: %v", fileName, err)
+					}
+				}
+			}
+		}
+
+		manifestYaml, err := yaml.Marshal(manifest)
+		if err == nil {
+			if w, err := zw.Create("manifest.yaml"); err == nil {
+				_, _ = w.Write(manifestYaml)
+			}
+		}
+
+		if err := zw.Close(); err != nil {
+			log.Warnf("brains: failed to finalize curation archive: %v", err)
+			return
+		}
+
+		event.Publish("brains", event.BrainsCompleted, gin.H{
+			"action":    "curate_download",
+			"album_uid": albumUID,
+			"count":     len(manifest),
+		})
+	})
+}
+
+// addFileToZip copies the file at sourcePath into zw under archiveName.
+func addFileToZip(zw *zip.Writer, sourcePath, archiveName string) error {
+	f, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := zw.Create(archiveName)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// publishCurationLink creates or refreshes the entity.Link shared for
+// albumUID so repeated CurateBrainsCollections calls with the same share
+// settings update one link instead of accumulating a new one per run.
+func publishCurationLink(albumUID string, share form.BrainsCurateShare) (link entity.Link, err error) {
+	links, err := entity.FindLinks(albumUID, "")
+	if err != nil {
+		return link, err
+	}
+
+	if len(links) > 0 {
+		link = links[0]
+	} else {
+		link = entity.NewLink(albumUID, true, false)
+	}
+
+	if share.SlugPrefix != "" {
+		link.ShareSlug = txt.Lower(strings.TrimSpace(share.SlugPrefix)) + "-" + rnd.GenerateUID('s')
+	}
+
+	if share.Expires > 0 {
+		link.ShareExpires = share.Expires
+	}
+
+	if share.MaxViews > 0 {
+		link.MaxViews = uint(share.MaxViews)
+	}
+
+	if share.Password != "" {
+		if err = link.SetPassword(share.Password); err != nil {
+			return link, err
+		}
+	}
+
+	if err = link.Save(); err != nil {
+		return link, err
+	}
+
+	return link, nil
+}
+
+// curatedAlbumResult is one entry returned by GetBrainsCurations: a
+// BRAINS-curated album and the share tokens currently published for it.
+type curatedAlbumResult struct {
+	AlbumUID string   `json:"album_uid"`
+	Title    string   `json:"title"`
+	Tokens   []string `json:"tokens"`
+}
+
+// GetBrainsCurations lists every album BRAINS has auto-curated, alongside
+// the tokens of any share links currently published for it, so an admin can
+// see what's already shared before publishing more.
+func GetBrainsCurations(router *gin.RouterGroup) {
+	router.GET("/api/v1/brains/curate", func(c *gin.Context) {
+		s := Auth(c, acl.ResourceConfig, acl.ActionRead)
+
+		if !s.Admin() {
+			AbortForbidden(c)
+			return
+		}
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		curator := brains.NewCurator(entity.Db())
+
+		albums, err := curator.CuratedAlbums()
+		if err != nil {
+			AbortSaveFailed(c, i18n.ErrUnexpected)
+			return
+		}
+
+		result := make([]curatedAlbumResult, 0, len(albums))
+
+		for _, album := range albums {
+			links, linkErr := entity.FindLinks(album.AlbumUID, "")
+			if linkErr != nil {
+				continue
+			}
+
+			var tokens []string
+			for _, link := range links {
+				tokens = append(tokens, link.ShareToken)
+			}
+
+			result = append(result, curatedAlbumResult{
+				AlbumUID: album.AlbumUID,
+				Title:    album.AlbumTitle,
+				Tokens:   tokens,
+			})
+		}
+
+		c.JSON(http.StatusOK, result)
+	})
+}
+
+// DeleteBrainsCurationLink revokes one share link published for a curated
+// album, so a token that leaked or is no longer wanted can be retired
+// without touching the album or any other link.
+func DeleteBrainsCurationLink(router *gin.RouterGroup) {
+	router.DELETE("/api/v1/brains/curate/:album_uid/link/:token", func(c *gin.Context) {
+		s := Auth(c, acl.ResourceConfig, acl.ActionUpdate)
+
+		if !s.Admin() {
+			AbortForbidden(c)
+			return
+		}
+
+		conf := Config(c)
+
+		if !conf.BrainsEnabled() {
+			AbortBadRequest(c, i18n.ErrFeatureDisabled)
+			return
+		}
+
+		albumUID := c.Param("album_uid")
+		token := c.Param("token")
+
+		link, err := entity.FindLink(albumUID, token)
+		if err != nil {
+			AbortEntityNotFound(c)
+			return
+		}
+
+		if err := link.Delete(); err != nil {
+			AbortSaveFailed(c, i18n.ErrUnexpected)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "share link removed",
+		})
+	})
+}
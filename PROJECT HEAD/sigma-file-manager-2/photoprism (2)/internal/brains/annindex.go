@@ -0,0 +1,150 @@
+package brains
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// ANNIndex is the approximate-nearest-neighbor backend EmbeddingIndex
+// searches against. The default FlatIndex is an exact brute-force scan;
+// swap in an HNSW or IVF implementation for large libraries by passing it
+// to NewEmbeddingIndex instead.
+type ANNIndex interface {
+	// Upsert adds or replaces the vector stored under id.
+	Upsert(id string, vec []float32)
+
+	// Remove deletes id's vector, if any.
+	Remove(id string)
+
+	// Search returns up to k ids nearest to vec, best match first.
+	Search(vec []float32, k int) []ScoredID
+
+	// Len reports how many vectors are currently indexed.
+	Len() int
+
+	// Save persists the index to path so a restart can warm-start instead
+	// of rebuilding from the database.
+	Save(path string) error
+
+	// Load replaces the index's contents with what was last saved to path.
+	Load(path string) error
+
+	// New returns a fresh, empty index configured the same way as this one,
+	// so Rebuild can repopulate a same-kind backend instead of always
+	// falling back to a brute-force index.
+	New() ANNIndex
+}
+
+// ScoredID is one ANNIndex search result: a vector's id and its similarity
+// score to the query vector, highest first.
+type ScoredID struct {
+	ID    string
+	Score float32
+}
+
+// FlatIndex is a brute-force ANNIndex: Search scores every stored vector by
+// cosine similarity. O(n) per query, but exact and simple enough to be the
+// default for libraries too small to need a true ANN structure.
+type FlatIndex struct {
+	mutex   sync.RWMutex
+	vectors map[string][]float32
+}
+
+// NewFlatIndex returns an empty FlatIndex.
+func NewFlatIndex() *FlatIndex {
+	return &FlatIndex{vectors: make(map[string][]float32)}
+}
+
+// Upsert implements ANNIndex.
+func (idx *FlatIndex) Upsert(id string, vec []float32) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.vectors[id] = vec
+}
+
+// Remove implements ANNIndex.
+func (idx *FlatIndex) Remove(id string) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	delete(idx.vectors, id)
+}
+
+// Search implements ANNIndex.
+func (idx *FlatIndex) Search(vec []float32, k int) []ScoredID {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	results := make([]ScoredID, 0, len(idx.vectors))
+
+	for id, stored := range idx.vectors {
+		if len(stored) != len(vec) {
+			continue
+		}
+
+		results = append(results, ScoredID{ID: id, Score: cosineSimilarity(vec, stored)})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if k > 0 && len(results) > k {
+		results = results[:k]
+	}
+
+	return results
+}
+
+// New implements ANNIndex.
+func (idx *FlatIndex) New() ANNIndex {
+	return NewFlatIndex()
+}
+
+// Len implements ANNIndex.
+func (idx *FlatIndex) Len() int {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	return len(idx.vectors)
+}
+
+// Save implements ANNIndex, gob-encoding the index to path.
+func (idx *FlatIndex) Save(path string) error {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("brains: failed to create index file: %v", err)
+	}
+	defer f.Close()
+
+	return gob.NewEncoder(f).Encode(idx.vectors)
+}
+
+// Load implements ANNIndex, replacing the index's contents with what was
+// gob-encoded to path by a previous Save.
+func (idx *FlatIndex) Load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var vectors map[string][]float32
+	if err := gob.NewDecoder(f).Decode(&vectors); err != nil {
+		return fmt.Errorf("brains: failed to decode index file: %v", err)
+	}
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.vectors = vectors
+
+	return nil
+}
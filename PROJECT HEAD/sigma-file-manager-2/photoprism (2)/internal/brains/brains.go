@@ -1,11 +1,17 @@
 package brains
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"image"
 	"os"
 	"path/filepath"
 	"runtime"
+	"runtime/debug"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/photoprism/photoprism/internal/config"
@@ -18,6 +24,28 @@ import (
 // Log outputs messages to log.
 var Log = event.Log
 
+// ANN backend names accepted by config.BrainsANNBackend(). Flat is the
+// exact brute-force default; LSH trades a little recall for sub-linear
+// query time on large libraries.
+const (
+	ANNBackendFlat = "flat"
+	ANNBackendLSH  = "lsh"
+)
+
+// brainsLSHSeed seeds LSHIndex's random hyperplanes so a freshly started
+// index (before any Load) hashes the same way across restarts.
+const brainsLSHSeed = 1
+
+// UpdateResults is set whenever ProcessFiles writes a new BrainsResult, and
+// UpdateCurations is set whenever a curation theme is added or modified. The
+// scheduler and curator loops check-and-clear these flags before doing any
+// DB or disk work, so idle libraries don't wake disks or CPU for nothing.
+var (
+	UpdateResults   atomic.Bool
+	UpdateCurations atomic.Bool
+	UpdateFaces     atomic.Bool
+)
+
 // Brains represents the main BRAINS service for enhanced photo analysis.
 type Brains struct {
 	conf          *config.Config
@@ -33,6 +61,13 @@ type Brains struct {
 	db            *entity.Db
 	query         *query.Query
 	scheduler     *Scheduler // Added scheduler for automation
+	warmer        *CacheWarmer
+	modelRegistry *ModelRegistry
+	embeddings    *EmbeddingIndex
+	pipeline      *Pipeline
+	faceEmbedder  *FaceEmbedder
+	faceClusterer *FaceClusterer
+	dispatcher    Dispatcher
 }
 
 // New returns a new BRAINS instance.
@@ -51,9 +86,34 @@ func New(conf *config.Config) *Brains {
 		cache:         NewCache(cachePath),
 	}
 
+	b.warmer = NewCacheWarmer(b)
+	b.pipeline = NewPipeline(b)
+	b.faceEmbedder = NewFaceEmbedder(b.modelPath)
+	b.faceClusterer = NewFaceClusterer()
+
 	return b
 }
 
+// Pipeline returns this instance's Pipeline, so callers can Enqueue files
+// for durable, resumable processing instead of blocking on ProcessFiles, or
+// inspect its Stats for throughput and backlog depth.
+func (b *Brains) Pipeline() *Pipeline {
+	return b.pipeline
+}
+
+// Warmer returns this instance's CacheWarmer, so the photo scanner and
+// manual "reprocess" endpoints can push newly indexed or edited photo IDs
+// to it without waiting for the scheduler to pick them up.
+func (b *Brains) Warmer() *CacheWarmer {
+	return b.warmer
+}
+
+// schedulerBusy reports whether this instance's scheduler is actively
+// processing a job, so CacheWarmer can back off and avoid contention.
+func (b *Brains) schedulerBusy() bool {
+	return b.scheduler != nil && b.scheduler.Busy()
+}
+
 // calculateOptimalBatchSize determines batch size based on available resources.
 func calculateOptimalBatchSize() int {
 	cpuCores := runtime.NumCPU()
@@ -88,27 +148,111 @@ func (b *Brains) Init() error {
 	b.db = entity.Db()
 	b.query = query.New(b.db)
 
-	// Check and create database table if needed
-	if err := b.db.AutoMigrate(&entity.BrainsResult{}).Error; err != nil {
+	// Check and create database tables if needed
+	if err := b.db.AutoMigrate(&entity.BrainsResult{}, &entity.BrainsJob{}, &entity.BrainsJobEvent{}, &entity.BrainsSchedulerState{}, &entity.BrainsModelMigration{}, &entity.BrainsQueue{}, &entity.BrainsDeadLetter{}, &entity.BrainsObject{}, &entity.BrainsKeyword{}).Error; err != nil {
 		return fmt.Errorf("brains: failed to migrate database schema: %v", err)
 	}
 
+	// Backfill the object/keyword index tables once for databases that
+	// predate them, so Object/Keyword search works without reprocessing
+	// every photo.
+	var objectCount int64
+	b.db.Model(&entity.BrainsObject{}).Count(&objectCount)
+
+	if objectCount == 0 {
+		if objects, keywords, backfillErr := entity.MigrateBrainsObjectsAndKeywords(); backfillErr != nil {
+			Log.Warnf("brains: failed to backfill object/keyword index: %v", backfillErr)
+		} else if objects > 0 || keywords > 0 {
+			Log.Infof("brains: backfilled %d objects and %d keywords into the index", objects, keywords)
+		}
+	}
+
+	// Re-import YAML sidecars into empty BrainsResult rows, so a database
+	// wipe doesn't force every photo to be reprocessed from scratch.
+	var resultCount int64
+	b.db.Model(&entity.BrainsResult{}).Count(&resultCount)
+
+	if resultCount == 0 && b.conf.BackupYaml() {
+		if imported, loadErr := b.LoadBrainsResultsFromYaml(); loadErr != nil {
+			Log.Warnf("brains: failed to restore results from YAML sidecars: %v", loadErr)
+		} else if imported > 0 {
+			Log.Infof("brains: restored %d results from YAML sidecars", imported)
+		}
+	}
+
 	// Load model versions
 	if err := b.loadModelVersions(); err != nil {
 		Log.Warnf("brains: failed to load model versions: %v", err)
 	}
 
+	if url := b.conf.BrainsModelRegistryURL(); url != "" {
+		b.modelRegistry = NewModelRegistry(b.conf, b.modelPath, url, b.conf.BrainsModelPublicKey())
+	}
+
+	b.cache.SetFingerprint(ModelFingerprint(b.modelVersions))
+	b.cache.SetMaxAge(b.conf.BrainsCacheTTL())
+	b.cache.SetMaxSize(b.conf.BrainsCacheMaxSize())
+	b.cache.StartSweeper(0)
+
 	// Initialize processors
 	if err := b.initProcessors(); err != nil {
 		return fmt.Errorf("brains: failed to initialize processors: %v", err)
 	}
 
+	b.warmupProcessors()
+
+	// Load the in-memory vector search index, warm-starting from its
+	// persisted file if one exists instead of rebuilding from scratch.
+	// Libraries configured for the approximate backend get an LSH index
+	// instead of the brute-force default, trading a little recall for
+	// sub-linear query time.
+	var annBackend ANNIndex = NewFlatIndex()
+
+	if b.conf.BrainsANNBackend() == ANNBackendLSH {
+		annBackend = NewLSHIndex(CLIPEmbeddingDims, brainsLSHSeed)
+	}
+
+	b.embeddings = NewEmbeddingIndex(annBackend, b.cachePath)
+	if err := b.embeddings.Load(); err != nil {
+		Log.Warnf("brains: failed to load embedding index: %v", err)
+	}
+	b.embeddings.StartPersister(nil)
+
+	b.dispatcher = NewDispatcher(b)
+
 	b.initialized = true
 	Log.Info("brains: initialization complete")
 
 	return nil
 }
 
+// Dispatcher returns this instance's Dispatcher, so API handlers can fan
+// processing out across a remote worker cluster instead of always calling
+// ProcessFiles in-process.
+func (b *Brains) Dispatcher() Dispatcher {
+	if !b.initialized {
+		_ = b.Init()
+	}
+
+	return b.dispatcher
+}
+
+// Workers returns every worker registered with this instance's Dispatcher,
+// for GET /api/v1/brains/workers. It's empty when running in local mode.
+func (b *Brains) Workers() []WorkerInfo {
+	pool, ok := b.Dispatcher().(*WorkerPool)
+	if !ok {
+		return nil
+	}
+
+	infos := make([]WorkerInfo, 0, len(pool.Workers()))
+	for _, worker := range pool.Workers() {
+		infos = append(infos, worker.Info())
+	}
+
+	return infos
+}
+
 // StartScheduler initializes and starts the automated scheduler.
 func (b *Brains) StartScheduler() error {
 	if !b.initialized {
@@ -192,33 +336,42 @@ func (b *Brains) loadModelVersions() error {
 	return nil
 }
 
-// initProcessors sets up the different BRAINS processors.
+// initProcessors builds every processor registered with RegisterProcessor,
+// so third parties can add their own without touching this package. A
+// processor whose factory returns an error is skipped rather than failing
+// initialization outright. The built-in processors construct successfully
+// even when their model files are missing, so HasCapability reports them as
+// available and their Process calls fail until the models are installed.
 func (b *Brains) initProcessors() error {
-	// Initialize object detection processor
-	objectProcessor, err := NewObjectProcessor(b.conf, b.modelPath)
-	if err != nil {
-		return err
+	for name, processor := range defaultRegistry.NewAll(b.conf, b.modelPath) {
+		b.processors[name] = processor
+		b.capabilities[processor.Capability()] = true
 	}
-	b.processors["object"] = objectProcessor
-	b.capabilities["object_detection"] = true
 
-	// Initialize aesthetic scoring processor
-	aestheticProcessor, err := NewAestheticProcessor(b.conf, b.modelPath)
-	if err != nil {
-		return err
+	if len(b.processors) == 0 {
+		return fmt.Errorf("no BRAINS processors available, run 'photoprism brains download' first")
 	}
-	b.processors["aesthetic"] = aestheticProcessor
-	b.capabilities["aesthetic_scoring"] = true
 
-	// Initialize scene understanding processor
-	sceneProcessor, err := NewSceneProcessor(b.conf, b.modelPath)
-	if err != nil {
-		return err
+	return nil
+}
+
+// warmupProcessors loads every enabled processor's model in parallel, so
+// the first file Init's caller submits doesn't pay each model's load cost
+// serially. It's best-effort: a processor that fails to warm up still gets
+// loaded lazily, on demand, by its own GetModel call.
+func (b *Brains) warmupProcessors() {
+	var wg sync.WaitGroup
+
+	for _, processor := range b.processors {
+		wg.Add(1)
+
+		go func(processor Processor) {
+			defer wg.Done()
+			processor.Warmup(context.Background())
+		}(processor)
 	}
-	b.processors["scene"] = sceneProcessor
-	b.capabilities["scene_understanding"] = true
 
-	return nil
+	wg.Wait()
 }
 
 // ProcessFiles analyzes a batch of files using the BRAINS neural system.
@@ -230,26 +383,38 @@ func (b *Brains) ProcessFiles(files []string) (*ProcessingResults, error) {
 	}
 
 	Log.Infof("brains: processing %d files", len(files))
-	
+
 	results := NewProcessingResults()
-	
+	tracker := newProgressTracker(len(files))
+
+	var firstErr error
+
 	// Process files in batches
 	for i := 0; i < len(files); i += b.batchSize {
 		end := i + b.batchSize
 		if end > len(files) {
 			end = len(files)
 		}
-		
+
 		batch := files[i:end]
-		batchResults, err := b.processBatch(batch)
+		batchResults, err := b.processBatch(batch, tracker.fileDone, nil)
 		if err != nil {
 			Log.Errorf("brains: error processing batch: %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
 			continue
 		}
-		
+
 		results.Merge(batchResults)
 	}
-	
+
+	if firstErr != nil {
+		publishProgress(tracker.failed(firstErr))
+	} else {
+		publishProgress(tracker.completed())
+	}
+
 	return results, nil
 }
 
@@ -297,16 +462,16 @@ func (b *Brains) ProcessFile(filePath string) (*FileResult, error) {
 // saveResultsToDatabase stores the processing results in the database.
 func (b *Brains) saveResultsToDatabase(results *ProcessingResults) error {
 	for _, fileResult := range results.Files {
-		// Get photo ID from path
+		// Get photo from path
 		fileName := filepath.Base(fileResult.Path)
-		var photoID string
-		
-		if photo, err := b.query.PhotoByName(fileName); err == nil {
-			photoID = photo.ID
-		} else {
+
+		photo, err := b.query.PhotoByName(fileName)
+		if err != nil {
 			Log.Warnf("brains: couldn't find photo ID for %s: %v", fileName, err)
 			continue
 		}
+
+		photoID := photo.ID
 		
 		// Create or update BRAINS result
 		brainsResult, err := entity.GetOrCreateBrainsResult(photoID)
@@ -315,96 +480,134 @@ func (b *Brains) saveResultsToDatabase(results *ProcessingResults) error {
 			continue
 		}
 
-		// Update result with new data
-		if aesthetic, ok := fileResult.Results["aesthetic"].(AestheticResult); ok {
-			brainsResult.AestheticScore = aesthetic.Score
-			brainsResult.Composition = aesthetic.Composition
-			brainsResult.Contrast = aesthetic.Contrast
-			brainsResult.Exposure = aesthetic.Exposure
-			brainsResult.ColorHarmony = aesthetic.ColorHarmony
-		}
-		
-		if scene, ok := fileResult.Results["scene"].(SceneResult); ok {
-			brainsResult.SceneType = scene.SceneType
-			brainsResult.IndoorOutdoor = scene.IndoorOutdoor
-			brainsResult.TimeOfDay = scene.TimeOfDay
-			brainsResult.Weather = scene.Weather
-			brainsResult.Keywords = strings.Join(scene.Keywords, ",")
-			
-			// Sort keywords alphabetically for consistent searching
-			sorted := append([]string{}, scene.Keywords...)
-			sort.Strings(sorted)
-			brainsResult.KeywordsSorted = strings.Join(sorted, ",")
-			
-			// Save emotions as JSON
-			if len(scene.Emotions) > 0 {
-				if emotionsJSON, err := json.Marshal(scene.Emotions); err == nil {
-					brainsResult.Emotions = string(emotionsJSON)
-				}
-			}
-		}
-		
-		if object, ok := fileResult.Results["object"].(ObjectResult); ok {
-			if objectJSON, err := json.Marshal(object.Objects); err == nil {
-				brainsResult.ObjectResults = string(objectJSON)
+		// Let each processor apply its own result fields onto brainsResult,
+		// so this loop doesn't need to know about every processor's shape.
+		for name, processorResult := range fileResult.Results {
+			processor, ok := b.processors[name]
+			if !ok {
+				continue
 			}
+
+			processor.MergeResult(brainsResult, processorResult)
 		}
-		
+
 		// Update processing time
 		brainsResult.ProcessedAt = sql.NullTime{Time: time.Now(), Valid: true}
-		
+
 		// Save to database
 		if err := brainsResult.Save(); err != nil {
 			Log.Errorf("brains: failed to save result to database: %v", err)
 			continue
 		}
+
+		// Keep the normalized object/keyword index tables in sync, so
+		// Object/Keyword search can JOIN on an indexed column instead of
+		// scanning ObjectResults/Keywords with LIKE.
+		if err := entity.SyncBrainsObjects(photoID, brainsResult.ObjectResults); err != nil {
+			Log.Warnf("brains: failed to sync object index for %s: %v", photoID, err)
+		}
+
+		if err := entity.SyncBrainsKeywords(photoID, "scene", strings.Split(brainsResult.KeywordsSorted, ",")); err != nil {
+			Log.Warnf("brains: failed to sync keyword index for %s: %v", photoID, err)
+		}
+
+		// Back up the result as a YAML sidecar, so it survives a database
+		// wipe without reprocessing every photo. No-op unless BackupYaml is
+		// enabled.
+		if err := b.SaveBrainsResultAsYaml(photo, *brainsResult); err != nil {
+			Log.Warnf("brains: failed to save YAML sidecar for %s: %v", photoID, err)
+		}
+
+		// Keep the vector search index in sync incrementally, so newly
+		// processed photos are searchable without waiting for a rebuild.
+		if b.embeddings != nil && brainsResult.Embedding != "" {
+			b.embeddings.Upsert(photoID, brainsResult.Embedding)
+		}
+
+		UpdateResults.Store(true)
 	}
-	
+
 	return nil
 }
 
-// processBatch handles processing of a single batch of files.
-func (b *Brains) processBatch(batch []string) (*ProcessingResults, error) {
+// processBatch handles processing of a single batch of files. onFile, if
+// not nil, is called exactly once per file once it's done (cached, skipped,
+// or processed), reporting its size and any processing error, so the
+// caller can track overall progress across batches. onEvent, if not nil, is
+// called at every state transition a file goes through (Scheduling,
+// Running, then one of Succeeded/Failed/Skipped), so a caller tracking a
+// SubmitFiles job can persist a JobEvent for each one.
+func (b *Brains) processBatch(batch []string, onFile func(path string, size int64, err error), onEvent func(file, state, reason, message string)) (*ProcessingResults, error) {
 	results := NewProcessingResults()
-	
+
 	var wg sync.WaitGroup
 	resultsMutex := sync.Mutex{}
 	errors := make([]error, 0)
 	errorsMutex := sync.Mutex{}
-	
+
 	// Enhanced: Use adaptive concurrency based on system resources
 	maxConcurrent := runtime.NumCPU()
 	if maxConcurrent > 4 {
 		// Use 75% of available cores for batch processing
 		maxConcurrent = int(float64(maxConcurrent) * 0.75)
 	}
-	
+
 	// Use a semaphore to limit concurrency
 	sem := make(chan bool, maxConcurrent)
-	
+
 	// Process each file in the batch with controlled concurrency
 	for _, filePath := range batch {
 		wg.Add(1)
+
+		if onEvent != nil {
+			onEvent(filePath, entity.JobEventScheduling, "", "")
+		}
+
 		sem <- true // Acquire semaphore
-		
+
 		go func(path string) {
+			var size int64
+			if info, err := os.Stat(path); err == nil {
+				size = info.Size()
+			}
+
+			var fileErr error
+
 			defer func() {
 				<-sem // Release semaphore
-				
+
 				if r := recover(); r != nil {
 					Log.Errorf("brains: panic recovered when processing %s: %v", path, r)
 					debug.PrintStack()
+
+					if onEvent != nil {
+						onEvent(path, entity.JobEventFailed, "panic", fmt.Sprintf("%v", r))
+					}
+				}
+
+				if onFile != nil {
+					onFile(path, size, fileErr)
 				}
-				
+
 				wg.Done()
 			}()
-			
+
+			if onEvent != nil {
+				onEvent(path, entity.JobEventRunning, "", "")
+			}
+
 			// Skip if file doesn't exist
 			if !fs.FileExists(path) {
 				Log.Warnf("brains: file not found: %s", path)
+				fileErr = fmt.Errorf("file not found: %s", path)
+
+				if onEvent != nil {
+					onEvent(path, entity.JobEventSkipped, "missing", fileErr.Error())
+				}
+
 				return
 			}
-			
+
 			// Check cache first
 			if cached, ok := b.cache.Get(path); ok {
 				for _, file := range cached.Files {
@@ -412,49 +615,69 @@ func (b *Brains) processBatch(batch []string) (*ProcessingResults, error) {
 						resultsMutex.Lock()
 						results.Files = append(results.Files, file)
 						resultsMutex.Unlock()
+
+						if onEvent != nil {
+							onEvent(path, entity.JobEventSucceeded, "cache", "")
+						}
+
 						return
 					}
 				}
 			}
-			
-			// Process with each available processor
-			fileResults := NewFileResult(path)
-			
-			for name, processor := range b.processors {
-				// Skip disabled processors
-				if !b.conf.BrainsCapabilities()[name+"_detection"] && 
-				   !b.conf.BrainsCapabilities()[name+"_scoring"] && 
-				   !b.conf.BrainsCapabilities()[name+"_understanding"] {
-					continue
+
+			// Process with each available processor, routing HEIC/RAW/video
+			// inputs through runProcessors' format gate first.
+			fileResults, err := b.runProcessors(path, func(name string, err error) {
+				Log.Warnf("brains: %s processor failed for %s: %v", name, path, err)
+				errorsMutex.Lock()
+				errors = append(errors, fmt.Errorf("processing %s with %s: %v", path, name, err))
+				errorsMutex.Unlock()
+				fileErr = err
+
+				if onEvent != nil {
+					onEvent(path, entity.JobEventRunning, name, err.Error())
 				}
-				
-				processorResults, err := processor.Process(path)
-				if err != nil {
-					Log.Warnf("brains: %s processor failed for %s: %v", name, path, err)
-					errorsMutex.Lock()
-					errors = append(errors, fmt.Errorf("processing %s with %s: %v", path, name, err))
-					errorsMutex.Unlock()
-					continue
+			})
+			if err != nil {
+				Log.Warnf("brains: %s", err)
+				fileErr = err
+
+				if onEvent != nil {
+					onEvent(path, entity.JobEventFailed, "input", err.Error())
 				}
-				
-				fileResults.Results[name] = processorResults
+
+				return
 			}
-			
+
+			for name := range fileResults.Results {
+				if onEvent != nil {
+					onEvent(path, entity.JobEventRunning, name, "succeeded")
+				}
+			}
+
 			// Add to overall results
 			resultsMutex.Lock()
 			results.Files = append(results.Files, fileResults)
 			resultsMutex.Unlock()
-			
+
+			if onEvent != nil {
+				if fileErr != nil {
+					onEvent(path, entity.JobEventFailed, "processor", fileErr.Error())
+				} else {
+					onEvent(path, entity.JobEventSucceeded, "", "")
+				}
+			}
+
 		}(filePath)
 	}
-	
+
 	wg.Wait()
-	
+
 	// Return first error if any occurred
 	if len(errors) > 0 {
 		return results, errors[0]
 	}
-	
+
 	return results, nil
 }
 
@@ -490,33 +713,291 @@ func (b *Brains) GetModelVersion(modelType string) string {
 	return "unknown"
 }
 
-// CheckForModelUpdates checks if model updates are available.
+// ModelFingerprint returns a stable summary of every loaded model's
+// version, for comparing against a watermark to tell whether any model has
+// been upgraded since that watermark was recorded.
+func (b *Brains) ModelFingerprint() string {
+	if !b.initialized {
+		_ = b.Init()
+	}
+
+	return ModelFingerprint(b.modelVersions)
+}
+
+// CheckForModelUpdates polls the remote model registry's manifest and
+// reports whether any model type's published version differs from what's
+// currently installed. It costs a single round trip when nothing changed,
+// since FetchManifest sends If-Modified-Since from the last check.
 func (b *Brains) CheckForModelUpdates() (bool, error) {
-	// This would typically check a remote server for updates
-	// For now, just return a placeholder implementation
-	return false, nil
+	if !b.initialized {
+		if err := b.Init(); err != nil {
+			return false, err
+		}
+	}
+
+	if b.modelRegistry == nil {
+		return false, fmt.Errorf("brains: no model registry configured")
+	}
+
+	updates, err := b.modelRegistry.CheckForUpdates(b.modelVersions)
+	if err != nil {
+		return false, err
+	}
+
+	return len(updates) > 0, nil
 }
 
-// UpdateModels downloads and installs updated BRAINS models.
-func (b *Brains) UpdateModels() error {
-	scriptPath := filepath.Join(b.conf.AppPath(), "scripts", "download-brains.sh")
-	if (!fs.FileExists(scriptPath)) {
-		return fmt.Errorf("download script not found: %s", scriptPath)
+// DescribeModelUpdates polls the remote model registry's manifest and
+// returns a from/to/size diff for every model type that would change,
+// so an admin can review what UpdateModels would download before
+// confirming it.
+func (b *Brains) DescribeModelUpdates() ([]ModelUpdateDiff, error) {
+	if !b.initialized {
+		if err := b.Init(); err != nil {
+			return nil, err
+		}
 	}
-	
-	if err := fs.Shell("bash", scriptPath); err != nil {
-		return fmt.Errorf("failed to update models: %v", err)
+
+	if b.modelRegistry == nil {
+		return nil, fmt.Errorf("brains: no model registry configured")
 	}
-	
-	// Reload model versions
+
+	return b.modelRegistry.DescribeUpdates(b.modelVersions)
+}
+
+// UpdateModels fetches the registry manifest and migrates every model type
+// whose published version differs from what's installed, swapping each
+// one's symlink atomically and recording the attempt in
+// brains_model_migrations. Processors are reloaded from the new symlink
+// targets once every migration completes, so an update takes effect
+// without restarting the service. If task is non-nil, its progress is
+// updated as each model downloads.
+func (b *Brains) UpdateModels(task *entity.Task) error {
+	if !b.initialized {
+		if err := b.Init(); err != nil {
+			return err
+		}
+	}
+
+	if b.modelRegistry == nil {
+		return fmt.Errorf("brains: no model registry configured")
+	}
+
+	updates, err := b.modelRegistry.CheckForUpdates(b.modelVersions)
+	if err != nil {
+		return fmt.Errorf("brains: failed to check for updates: %v", err)
+	}
+
+	var firstErr error
+
+	for i, entry := range updates {
+		completed := i
+
+		progress := func(percent int) {
+			if task == nil {
+				return
+			}
+
+			task.SetProgressPercent((completed*100 + percent) / len(updates))
+		}
+
+		if err := b.modelRegistry.MigrateModel(entry, progress); err != nil {
+			Log.Errorf("brains: failed to migrate model %s: %v", entry.Name, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		Log.Infof("brains: migrated model %s to version %s", entry.Name, entry.Version)
+	}
+
+	if task != nil {
+		task.SetProgressPercent(100)
+	}
+
+	// Reload model versions and processors so the new symlink targets take
+	// effect immediately.
 	if err := b.loadModelVersions(); err != nil {
 		Log.Warnf("brains: failed to reload model versions: %v", err)
 	}
-	
-	return nil
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if err := b.initProcessors(); err != nil {
+		Log.Warnf("brains: failed to reload processors after model update: %v", err)
+	}
+
+	return firstErr
+}
+
+// RollbackModel swaps name's symlink back to the target of its last
+// successful migration and reloads processors, for operators recovering
+// from a bad update.
+func (b *Brains) RollbackModel(name string) error {
+	if b.modelRegistry == nil {
+		return fmt.Errorf("brains: no model registry configured")
+	}
+
+	if err := b.modelRegistry.RollbackModel(name); err != nil {
+		return err
+	}
+
+	if err := b.loadModelVersions(); err != nil {
+		Log.Warnf("brains: failed to reload model versions: %v", err)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.initProcessors()
 }
 
 // ClearCache clears the BRAINS cache.
 func (b *Brains) ClearCache() error {
 	return b.cache.ClearAll()
 }
+
+// Shutdown stops accepting further cache-warming submissions and waits for
+// in-flight jobs on this instance's CacheWarmer to finish, or for ctx to be
+// canceled first, whichever happens sooner.
+func (b *Brains) Shutdown(ctx context.Context) error {
+	return b.warmer.Shutdown(ctx)
+}
+
+// SimilarPhotos returns up to k photos visually similar to photoID
+// according to their BRAINS embeddings, most similar first, with any of
+// filters applied after ranking (e.g. FilterSceneType, FilterIndoorOutdoor).
+func (b *Brains) SimilarPhotos(photoID string, k int, filters ...Filter) ([]SimilarResult, error) {
+	if !b.initialized {
+		if err := b.Init(); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.embeddings.SimilarPhotos(photoID, k, filters...)
+}
+
+// SearchByVector returns up to k photos whose embeddings are closest to
+// vec, most similar first, for callers that already have a query vector
+// rather than a reference photo (e.g. a text-to-image embedding).
+func (b *Brains) SearchByVector(vec []float32, k int, filters ...Filter) ([]SimilarResult, error) {
+	if !b.initialized {
+		if err := b.Init(); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.embeddings.SearchByVector(vec, k, filters...)
+}
+
+// EmbedFaces computes a FaceNet embedding for every marker on photo that
+// doesn't have one yet, cropping each face out of filePath using the
+// marker's existing bounding box, and persists the result on the marker.
+// Clustering markers into entity.Person records happens separately in
+// FaceClusterer, run periodically by the scheduler, so this stays fast
+// enough to call inline from IndexPhoto.
+func (b *Brains) EmbedFaces(filePath string, photo *entity.Photo) error {
+	if !b.initialized {
+		if err := b.Init(); err != nil {
+			return err
+		}
+	}
+
+	markers, err := entity.FindMarkersWithoutEmbedding(photo.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(markers) == 0 {
+		return nil
+	}
+
+	img, err := decodeImage(filePath)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+
+	for _, marker := range markers {
+		vec, err := b.faceEmbedder.Embed(img, markerBox(img, marker))
+		if err != nil {
+			Log.Warnf("brains: failed to embed face marker %s: %v", marker.MarkerUID, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		marker.Embedding = EncodeEmbedding(vec)
+
+		if err := marker.Save(); err != nil {
+			Log.Warnf("brains: failed to save face embedding for marker %s: %v", marker.MarkerUID, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		UpdateFaces.Store(true)
+	}
+
+	return firstErr
+}
+
+// markerBox converts marker's relative bounding box (X, Y, W, H as
+// fractions of the photo's width and height) into absolute pixel
+// coordinates within img.
+func markerBox(img image.Image, marker *entity.Marker) image.Rectangle {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	x0 := bounds.Min.X + int(marker.X*float32(w))
+	y0 := bounds.Min.Y + int(marker.Y*float32(h))
+	x1 := x0 + int(marker.W*float32(w))
+	y1 := y0 + int(marker.H*float32(h))
+
+	return image.Rect(x0, y0, x1, y1)
+}
+
+// SimilarFaces returns up to k markers whose face is most similar to
+// markerUID's, most similar first, so the UI can search "photos of this
+// person" from any detected face.
+func (b *Brains) SimilarFaces(markerUID string, k int) ([]SimilarMarker, error) {
+	if !b.initialized {
+		if err := b.Init(); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.faceClusterer.SimilarTo(markerUID, k)
+}
+
+// ClusterFaces groups every marker with a face embedding into entity.Person
+// records, for operators triggering clustering manually instead of waiting
+// for the scheduler's next pass.
+func (b *Brains) ClusterFaces() (int, error) {
+	if !b.initialized {
+		if err := b.Init(); err != nil {
+			return 0, err
+		}
+	}
+
+	return b.faceClusterer.Cluster()
+}
+
+// RebuildEmbeddingIndex repopulates the vector search index from scratch by
+// reading every BrainsResult's Embedding column, for operators recovering
+// from a corrupted persisted index or after swapping in a different
+// ANNIndex implementation.
+func (b *Brains) RebuildEmbeddingIndex() error {
+	if !b.initialized {
+		if err := b.Init(); err != nil {
+			return err
+		}
+	}
+
+	return b.embeddings.Rebuild()
+}
@@ -1,118 +1,279 @@
 package brains
 
 import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/photoprism/photoprism/pkg/fs"
 )
 
-// Cache represents a caching system for BRAINS processing results.
+// defaultCacheTTL is used for entries with no matching per-processor
+// override.
+const defaultCacheTTL = 24 * time.Hour
+
+// cacheSweepInterval is how often StartSweeper checks for stale and
+// fingerprint-mismatched entries by default.
+const cacheSweepInterval = time.Hour
+
+// CacheBackend stores and retrieves the raw, already-serialized bytes of a
+// cache entry under a content-addressed key. Implementations only need to
+// be a dumb key/value store; expiration and fingerprint invalidation are
+// handled by Cache itself so every backend gets them for free.
+type CacheBackend interface {
+	// Get returns the stored bytes for key and when they were written, or
+	// ok == false if no entry exists.
+	Get(key string) (data []byte, storedAt time.Time, ok bool)
+
+	// Set stores data under key.
+	Set(key string, data []byte) error
+
+	// Delete removes the entry for key, if any.
+	Delete(key string) error
+
+	// Keys returns every key currently stored, for ClearAll and the
+	// sweeper to walk.
+	Keys() ([]string, error)
+}
+
+// cacheEntry is the JSON envelope written to a CacheBackend. It wraps the
+// cached ProcessingResults with the model fingerprint that was active when
+// they were produced, so a sweeper can evict results made with a model that
+// has since been replaced even before their TTL expires.
+type cacheEntry struct {
+	Fingerprint string             `json:"fingerprint"`
+	StoredAt    time.Time          `json:"stored_at"`
+	Results     *ProcessingResults `json:"results"`
+}
+
+// Cache represents a caching system for BRAINS processing results. Entries
+// are keyed by the content hash of the source file plus the fingerprint of
+// the models that will process it, so edited files and upgraded models are
+// never served a stale result, and identical files in different directories
+// share one entry. Storage is delegated to a pluggable CacheBackend so the
+// same Cache logic works for the on-disk layout, an in-process LRU, or a
+// remote object store shared by a cluster of workers.
 type Cache struct {
-	cachePath string
-	mutex     sync.RWMutex
-	maxAge    time.Duration
+	backend     CacheBackend
+	mutex       sync.RWMutex
+	maxAge      time.Duration
+	maxBytes    int64
+	ttls        map[string]time.Duration
+	fingerprint string
+	sweepStop   chan struct{}
 }
 
-// NewCache returns a new BRAINS cache.
+// NewCache returns a new BRAINS cache backed by the filesystem layout at
+// cachePath, as used by a single-node install.
 func NewCache(cachePath string) *Cache {
-	// Ensure cache directory exists
-	if !fs.DirectoryExists(cachePath) {
-		if err := os.MkdirAll(cachePath, os.ModePerm); err != nil {
-			Log.Errorf("brains: failed to create cache directory: %v", err)
-		}
-	}
+	return NewCacheWithBackend(NewFileCacheBackend(cachePath))
+}
 
+// NewCacheWithBackend returns a new BRAINS cache using backend for storage,
+// so callers can swap in an in-process LRU or a shared object store without
+// changing anything else in this package.
+func NewCacheWithBackend(backend CacheBackend) *Cache {
 	return &Cache{
-		cachePath: cachePath,
-		maxAge:    24 * time.Hour, // Default cache expiration of 1 day
+		backend: backend,
+		maxAge:  defaultCacheTTL,
+		ttls:    make(map[string]time.Duration),
 	}
 }
 
-// Key generates a cache key for a file.
-func (c *Cache) Key(filename string) string {
-	return filepath.Base(filename)
+// SetTTL overrides the expiration for cache entries produced by processor,
+// instead of the default TTL. An entry contributed to by more than one
+// processor expires at the earliest of their overrides.
+func (c *Cache) SetTTL(processor string, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.ttls[processor] = ttl
 }
 
-// Path returns the full cache file path for a key.
-func (c *Cache) Path(key string) string {
-	return filepath.Join(c.cachePath, key+".json")
+// SetMaxAge overrides the default TTL applied to entries with no matching
+// per-processor override, so deployments can tune it via config.Config
+// instead of living with defaultCacheTTL.
+func (c *Cache) SetMaxAge(maxAge time.Duration) {
+	if maxAge <= 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.maxAge = maxAge
 }
 
-// Get retrieves cached results for a file.
-func (c *Cache) Get(filename string) (*ProcessingResults, bool) {
+// SetMaxSize bounds the total size of stored entries to maxBytes, evicting
+// the least recently written entries first once the sweeper runs. A value
+// <= 0 disables size-based eviction.
+func (c *Cache) SetMaxSize(maxBytes int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.maxBytes = maxBytes
+}
+
+// SetFingerprint records the fingerprint of the currently loaded models.
+// Entries cached under a different fingerprint are treated as stale, so Get
+// misses and the sweeper evicts them even if their TTL hasn't expired yet.
+// Brains calls this after loadModelVersions.
+func (c *Cache) SetFingerprint(fingerprint string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.fingerprint = fingerprint
+}
+
+// Key generates a content-addressed cache key for a file: the SHA256 of its
+// bytes combined with the fingerprint of the models that will process it,
+// so re-processing after an edit or a model upgrade happens automatically
+// and identical files across directories share one entry.
+func (c *Cache) Key(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", fmt.Errorf("error opening file for cache key: %v", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("error hashing file for cache key: %v", err)
+	}
+
 	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+	fingerprint := c.fingerprint
+	c.mutex.RUnlock()
 
-	key := c.Key(filename)
-	cachePath := c.Path(key)
+	if fingerprint != "" {
+		io.WriteString(h, "|"+fingerprint)
+	}
 
-	// Check if cache file exists and is not expired
-	info, err := os.Stat(cachePath)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Get retrieves cached results for a file.
+func (c *Cache) Get(filename string) (*ProcessingResults, bool) {
+	key, err := c.Key(filename)
 	if err != nil {
+		Log.Warnf("brains: %v", err)
 		return nil, false
 	}
 
-	// Check if cache is expired
-	if time.Since(info.ModTime()) > c.maxAge {
+	entry, ok := c.getEntry(key)
+	if !ok {
 		return nil, false
 	}
 
-	// Read and parse cache file
-	data, err := os.ReadFile(cachePath)
-	if err != nil {
-		Log.Warnf("brains: error reading cache file: %v", err)
+	return entry.Results, true
+}
+
+// getEntry reads and validates the cache entry for key, evicting it if it's
+// expired or was produced by a model fingerprint that's no longer current.
+func (c *Cache) getEntry(key string) (*cacheEntry, bool) {
+	data, storedAt, ok := c.backend.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		Log.Warnf("brains: error unmarshalling cache entry: %v", err)
+		return nil, false
+	}
+
+	if entry.StoredAt.IsZero() {
+		entry.StoredAt = storedAt
+	}
+
+	c.mutex.RLock()
+	fingerprint := c.fingerprint
+	c.mutex.RUnlock()
+
+	if fingerprint != "" && entry.Fingerprint != "" && entry.Fingerprint != fingerprint {
+		_ = c.backend.Delete(key)
 		return nil, false
 	}
 
-	var results ProcessingResults
-	if err := json.Unmarshal(data, &results); err != nil {
-		Log.Warnf("brains: error unmarshalling cache: %v", err)
+	if time.Since(entry.StoredAt) > c.ttl(entry.Results) {
+		_ = c.backend.Delete(key)
 		return nil, false
 	}
 
-	return &results, true
+	return &entry, true
 }
 
-// Set caches results for a file.
-func (c *Cache) Set(filename string, results *ProcessingResults) error {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+// ttl returns the expiration to apply to results: the earliest of any
+// per-processor override among the processors that contributed to it, or
+// the default TTL if none apply.
+func (c *Cache) ttl(results *ProcessingResults) time.Duration {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	ttl := c.maxAge
 
-	key := c.Key(filename)
-	cachePath := c.Path(key)
+	if results == nil {
+		return ttl
+	}
 
-	// Convert to JSON
-	data, err := json.MarshalIndent(results, "", "  ")
+	for _, file := range results.Files {
+		for processor := range file.Results {
+			if override, ok := c.ttls[processor]; ok && override < ttl {
+				ttl = override
+			}
+		}
+	}
+
+	return ttl
+}
+
+// Set caches results for a file under its content-addressed key.
+func (c *Cache) Set(filename string, results *ProcessingResults) error {
+	key, err := c.Key(filename)
 	if err != nil {
-		return fmt.Errorf("error marshalling results: %v", err)
+		return err
 	}
 
-	// Write to cache file
-	if err := os.WriteFile(cachePath, data, 0644); err != nil {
-		return fmt.Errorf("error writing cache file: %v", err)
+	c.mutex.RLock()
+	fingerprint := c.fingerprint
+	c.mutex.RUnlock()
+
+	entry := cacheEntry{
+		Fingerprint: fingerprint,
+		StoredAt:    time.Now(),
+		Results:     results,
 	}
 
-	return nil
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling results: %v", err)
+	}
+
+	return c.backend.Set(key, data)
 }
 
 // Clear removes cache entries for the given files.
 func (c *Cache) Clear(filenames []string) error {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
 	for _, filename := range filenames {
-		key := c.Key(filename)
-		cachePath := c.Path(key)
+		key, err := c.Key(filename)
+		if err != nil {
+			Log.Warnf("brains: %v", err)
+			continue
+		}
 
-		if fs.FileExists(cachePath) {
-			if err := os.Remove(cachePath); err != nil {
-				Log.Warnf("brains: error removing cache file: %v", err)
-			}
+		if err := c.backend.Delete(key); err != nil {
+			Log.Warnf("brains: error removing cache entry: %v", err)
 		}
 	}
 
@@ -121,26 +282,475 @@ func (c *Cache) Clear(filenames []string) error {
 
 // ClearAll removes all cache entries.
 func (c *Cache) ClearAll() error {
+	keys, err := c.backend.Keys()
+	if err != nil {
+		return fmt.Errorf("error listing cache entries: %v", err)
+	}
+
+	for _, key := range keys {
+		if err := c.backend.Delete(key); err != nil {
+			Log.Warnf("brains: error removing cache entry: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// StartSweeper starts a background goroutine that periodically evicts
+// entries that have expired or were produced by a model fingerprint that's
+// no longer current, so stale results don't linger until they happen to be
+// looked up again. It returns a stop function that halts the sweeper.
+func (c *Cache) StartSweeper(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = cacheSweepInterval
+	}
+
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	if c.sweepStop != nil {
+		close(c.sweepStop)
+	}
+	done := make(chan struct{})
+	c.sweepStop = done
+	c.mutex.Unlock()
+
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.sweep()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		if c.sweepStop == done {
+			close(done)
+			c.sweepStop = nil
+		}
+	}
+}
+
+// sweep evicts every entry that getEntry would reject, by simply looking
+// each one up; getEntry already deletes anything stale or fingerprint-
+// mismatched as a side effect of validating it. Once expired entries are
+// gone, it enforces the configured size cap, if any.
+func (c *Cache) sweep() {
+	keys, err := c.backend.Keys()
+	if err != nil {
+		Log.Warnf("brains: sweeper failed to list cache entries: %v", err)
+		return
+	}
+
+	evicted := 0
+	var remaining []lruItem
+
+	for _, key := range keys {
+		if _, ok := c.getEntry(key); !ok {
+			evicted++
+			continue
+		}
+
+		if data, storedAt, ok := c.backend.Get(key); ok {
+			remaining = append(remaining, lruItem{key: key, data: data, storedAt: storedAt})
+		}
+	}
+
+	if evicted > 0 {
+		Log.Debugf("brains: cache sweeper evicted %d stale entries", evicted)
+	}
+
+	evicted = c.enforceMaxSize(remaining)
+	if evicted > 0 {
+		Log.Debugf("brains: cache sweeper evicted %d entries to stay under the size limit", evicted)
+	}
+}
+
+// enforceMaxSize deletes the oldest entries in items, by StoredAt, until the
+// total size of what's left no longer exceeds maxBytes. It returns the
+// number of entries it removed.
+func (c *Cache) enforceMaxSize(items []lruItem) int {
+	c.mutex.RLock()
+	maxBytes := c.maxBytes
+	c.mutex.RUnlock()
+
+	if maxBytes <= 0 {
+		return 0
+	}
+
+	var total int64
+	for _, item := range items {
+		total += int64(len(item.data))
+	}
+
+	if total <= maxBytes {
+		return 0
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].storedAt.Before(items[j].storedAt)
+	})
+
+	evicted := 0
 
-	entries, err := os.ReadDir(c.cachePath)
+	for _, item := range items {
+		if total <= maxBytes {
+			break
+		}
+
+		if err := c.backend.Delete(item.key); err != nil {
+			Log.Warnf("brains: error evicting cache entry over size limit: %v", err)
+			continue
+		}
+
+		total -= int64(len(item.data))
+		evicted++
+	}
+
+	return evicted
+}
+
+// ModelFingerprint combines a set of model versions into a single stable
+// string suitable for Cache.SetFingerprint, so a change to any model
+// invalidates cached results regardless of map iteration order.
+func ModelFingerprint(versions map[string]string) string {
+	names := make([]string, 0, len(versions))
+	for name := range versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+versions[name])
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// FileCacheBackend stores cache entries as individual files on disk, the
+// layout the original single-node Cache used directly.
+type FileCacheBackend struct {
+	cachePath string
+}
+
+// NewFileCacheBackend returns a CacheBackend that stores entries as files
+// under cachePath, creating the directory if it doesn't exist yet.
+func NewFileCacheBackend(cachePath string) *FileCacheBackend {
+	if !fs.DirectoryExists(cachePath) {
+		if err := os.MkdirAll(cachePath, os.ModePerm); err != nil {
+			Log.Errorf("brains: failed to create cache directory: %v", err)
+		}
+	}
+
+	return &FileCacheBackend{cachePath: cachePath}
+}
+
+// path returns the on-disk path for key.
+func (b *FileCacheBackend) path(key string) string {
+	return filepath.Join(b.cachePath, key+".json")
+}
+
+// Get implements CacheBackend.
+func (b *FileCacheBackend) Get(key string) ([]byte, time.Time, bool) {
+	path := b.path(key)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("error reading cache directory: %v", err)
+		Log.Warnf("brains: error reading cache file: %v", err)
+		return nil, time.Time{}, false
 	}
 
+	return data, info.ModTime(), true
+}
+
+// Set implements CacheBackend.
+func (b *FileCacheBackend) Set(key string, data []byte) error {
+	return os.WriteFile(b.path(key), data, 0644)
+}
+
+// Delete implements CacheBackend.
+func (b *FileCacheBackend) Delete(key string) error {
+	path := b.path(key)
+
+	if !fs.FileExists(path) {
+		return nil
+	}
+
+	return os.Remove(path)
+}
+
+// Keys implements CacheBackend.
+func (b *FileCacheBackend) Keys() ([]string, error) {
+	entries, err := os.ReadDir(b.cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(entries))
+
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
 			continue
 		}
-		
-		if filepath.Ext(entry.Name()) == ".json" {
-			fullPath := filepath.Join(c.cachePath, entry.Name())
-			if err := os.Remove(fullPath); err != nil {
-				Log.Warnf("brains: error removing cache file: %v", err)
-			}
+
+		keys = append(keys, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+
+	return keys, nil
+}
+
+// lruItem is the value stored in LRUCacheBackend's linked list.
+type lruItem struct {
+	key      string
+	data     []byte
+	storedAt time.Time
+}
+
+// LRUCacheBackend is an in-process CacheBackend bounded by total byte size
+// rather than entry count, so a handful of large results can't starve many
+// small ones out of the cache. Least-recently-used entries are evicted
+// first once maxBytes is exceeded.
+type LRUCacheBackend struct {
+	mutex    sync.Mutex
+	maxBytes int64
+	curBytes int64
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+// NewLRUCacheBackend returns an in-process CacheBackend that evicts the
+// least-recently-used entries once the total size of stored entries would
+// exceed maxBytes.
+func NewLRUCacheBackend(maxBytes int64) *LRUCacheBackend {
+	return &LRUCacheBackend{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get implements CacheBackend.
+func (b *LRUCacheBackend) Get(key string) ([]byte, time.Time, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	el, ok := b.items[key]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+
+	b.order.MoveToFront(el)
+	item := el.Value.(*lruItem)
+
+	return item.data, item.storedAt, true
+}
+
+// Set implements CacheBackend.
+func (b *LRUCacheBackend) Set(key string, data []byte) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if el, ok := b.items[key]; ok {
+		b.curBytes -= int64(len(el.Value.(*lruItem).data))
+		el.Value = &lruItem{key: key, data: data, storedAt: time.Now()}
+		b.order.MoveToFront(el)
+	} else {
+		el := b.order.PushFront(&lruItem{key: key, data: data, storedAt: time.Now()})
+		b.items[key] = el
+	}
+
+	b.curBytes += int64(len(data))
+
+	for b.curBytes > b.maxBytes && b.order.Len() > 0 {
+		oldest := b.order.Back()
+		if oldest == nil {
+			break
 		}
+
+		item := oldest.Value.(*lruItem)
+		b.curBytes -= int64(len(item.data))
+		b.order.Remove(oldest)
+		delete(b.items, item.key)
+	}
+
+	return nil
+}
+
+// Delete implements CacheBackend.
+func (b *LRUCacheBackend) Delete(key string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	el, ok := b.items[key]
+	if !ok {
+		return nil
+	}
+
+	b.curBytes -= int64(len(el.Value.(*lruItem).data))
+	b.order.Remove(el)
+	delete(b.items, key)
+
+	return nil
+}
+
+// Keys implements CacheBackend.
+func (b *LRUCacheBackend) Keys() ([]string, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	keys := make([]string, 0, len(b.items))
+	for key := range b.items {
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// S3CacheBackend stores cache entries as objects in an S3/MinIO-compatible
+// bucket, so a cluster of BRAINS workers behind a shared endpoint can reuse
+// each other's results instead of recomputing them per node.
+type S3CacheBackend struct {
+	Endpoint  string
+	Bucket    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+	client    *http.Client
+}
+
+// NewS3CacheBackend returns a CacheBackend backed by the bucket at
+// endpoint, storing every object under keyPrefix.
+func NewS3CacheBackend(endpoint, bucket, keyPrefix, accessKey, secretKey string) *S3CacheBackend {
+	return &S3CacheBackend{
+		Endpoint:  strings.TrimSuffix(endpoint, "/"),
+		Bucket:    bucket,
+		Prefix:    keyPrefix,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		client:    http.DefaultClient,
+	}
+}
+
+// objectKey returns the full object key for a cache key.
+func (b *S3CacheBackend) objectKey(key string) string {
+	if b.Prefix == "" {
+		return key
+	}
+
+	return b.Prefix + "/" + key
+}
+
+// objectURL returns the full request URL for an object key.
+func (b *S3CacheBackend) objectURL(objectKey string) string {
+	return fmt.Sprintf("%s/%s/%s", b.Endpoint, b.Bucket, objectKey)
+}
+
+// authenticate applies this backend's credentials to req, if configured.
+func (b *S3CacheBackend) authenticate(req *http.Request) {
+	if b.AccessKey != "" {
+		req.SetBasicAuth(b.AccessKey, b.SecretKey)
+	}
+}
+
+// Get implements CacheBackend.
+func (b *S3CacheBackend) Get(key string) ([]byte, time.Time, bool) {
+	objectKey := b.objectKey(key)
+
+	req, err := http.NewRequest(http.MethodGet, b.objectURL(objectKey), nil)
+	if err != nil {
+		Log.Warnf("brains: error building S3 cache request: %v", err)
+		return nil, time.Time{}, false
+	}
+	b.authenticate(req)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		Log.Warnf("brains: error reading S3 cache entry: %v", err)
+		return nil, time.Time{}, false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, time.Time{}, false
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		Log.Warnf("brains: error reading S3 cache body: %v", err)
+		return nil, time.Time{}, false
+	}
+
+	storedAt := time.Now()
+	if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+		if t, err := time.Parse(http.TimeFormat, lastModified); err == nil {
+			storedAt = t
+		}
+	}
+
+	return data, storedAt, true
+}
+
+// Set implements CacheBackend.
+func (b *S3CacheBackend) Set(key string, data []byte) error {
+	objectKey := b.objectKey(key)
+
+	req, err := http.NewRequest(http.MethodPut, b.objectURL(objectKey), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("brains: error building S3 cache request: %v", err)
+	}
+	req.ContentLength = int64(len(data))
+	b.authenticate(req)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("brains: error writing S3 cache entry: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("brains: S3 cache put failed with status %d", resp.StatusCode)
 	}
 
 	return nil
 }
+
+// Delete implements CacheBackend.
+func (b *S3CacheBackend) Delete(key string) error {
+	objectKey := b.objectKey(key)
+
+	req, err := http.NewRequest(http.MethodDelete, b.objectURL(objectKey), nil)
+	if err != nil {
+		return fmt.Errorf("brains: error building S3 cache request: %v", err)
+	}
+	b.authenticate(req)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("brains: error deleting S3 cache entry: %v", err)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// Keys implements CacheBackend. S3-compatible object listing (the
+// ListObjectsV2 XML API) is out of scope for this backend; multi-node
+// deployments that need sweeper support should pair it with an LRU or file
+// backend for the metadata index.
+func (b *S3CacheBackend) Keys() ([]string, error) {
+	return nil, fmt.Errorf("brains: S3CacheBackend does not support listing keys")
+}
@@ -1,7 +1,7 @@
 package brains
 
 import (
-	"sort"
+	"fmt"
 	"sync"
 	"time"
 
@@ -10,7 +10,12 @@ import (
 	"github.com/photoprism/photoprism/internal/query"
 )
 
-// CurationTheme defines a theme for automatic curation of photos
+// CurationTheme defines a theme for automatic curation of photos. The flat
+// fields below are kept for JSON back-compat with themes authored before the
+// Predicate tree existed; they're auto-converted into an implicit And tree
+// by Predicate() when Rule is empty. New themes should set Rule instead,
+// either by building a Predicate tree directly or via ParsePredicate with a
+// DSL string such as "scene:landscape & !time:night & aesthetic:>=7".
 type CurationTheme struct {
 	Name            string   `json:"name"`
 	Description     string   `json:"description"`
@@ -24,6 +29,26 @@ type CurationTheme struct {
 	EmotionTypes    []string `json:"emotion_types,omitempty"`
 	MinObjects      int      `json:"min_objects"`
 	MaxItems        int      `json:"max_items"`
+	DSL             string   `json:"dsl,omitempty"`
+	Rule            Predicate `json:"-"`
+}
+
+// Predicate returns the theme's rule tree, parsing DSL or falling back to the
+// legacy flat fields if Rule was not set explicitly.
+func (t CurationTheme) Predicate() (Predicate, error) {
+	if t.Rule != nil {
+		return t.Rule, nil
+	}
+
+	if t.DSL != "" {
+		return ParsePredicate(t.DSL)
+	}
+
+	if legacy := legacyToPredicate(t); legacy != nil {
+		return legacy, nil
+	}
+
+	return nil, nil
 }
 
 // DefaultCurationThemes provides built-in themes for automatic curation
@@ -95,8 +120,17 @@ func NewCurator(db *entity.Db) *Curator {
 func (c *Curator) AddTheme(theme CurationTheme) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	
+
 	c.themes = append(c.themes, theme)
+	UpdateCurations.Store(true)
+}
+
+// Invalidate sets UpdateCurations so the next curator tick recomputes every
+// theme, even if nothing else in the library changed. themeName is only
+// logged; there is no per-theme dirty state.
+func (c *Curator) Invalidate(themeName string) {
+	UpdateCurations.Store(true)
+	Log.Debugf("curator: invalidated theme %q", themeName)
 }
 
 // GetThemes returns all available themes
@@ -157,6 +191,15 @@ func (c *Curator) CurateCollection(theme CurationTheme) (*entity.Album, error) {
 	return album, nil
 }
 
+// CuratedAlbums returns every album previously produced by CurateCollection,
+// identified by the "AI Curated" category CurateCollection tags them with,
+// so callers like the /api/v1/brains/curate listing route don't need to
+// know which themes exist to find them.
+func (c *Curator) CuratedAlbums() (albums []*entity.Album, err error) {
+	err = c.db.Where("album_category = ?", "AI Curated").Find(&albums).Error
+	return albums, err
+}
+
 // CurateAllCollections creates or updates collections for all themes
 func (c *Curator) CurateAllCollections() ([]*entity.Album, error) {
 	c.mutex.RLock()
@@ -177,75 +220,35 @@ func (c *Curator) CurateAllCollections() ([]*entity.Album, error) {
 	return albums, nil
 }
 
-// findPhotosForTheme finds photos that match a theme's criteria
+// findPhotosForTheme finds photos that match a theme's criteria by compiling
+// its Predicate tree into a single SQL query against brains_results joined
+// to photos, rather than merging/intersecting separate queries client-side.
 func (c *Curator) findPhotosForTheme(theme CurationTheme) (entity.Photos, error) {
-	// This is a placeholder implementation
-	// In a real implementation, we would use more sophisticated query building
-	
 	var foundPhotos entity.Photos
-	var err error
-	
-	// Start with aesthetic search as the base
-	if theme.MinAesthetic > 0 {
-		brainsSearch := c.query.Brains()
-		foundPhotos, err = brainsSearch.AestheticScore(theme.MinAesthetic, 10.0)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		// If no aesthetic filter, start with all photos
-		foundPhotos, err = c.query.Photos(5000)
-		if err != nil {
-			return nil, err
-		}
-	}
-	
-	// Filter by scene type if specified
-	if len(theme.SceneTypes) > 0 {
-		var sceneMatches entity.Photos
-		
-		for _, sceneType := range theme.SceneTypes {
-			photos, err := c.query.Brains().SceneType(sceneType)
-			if err != nil {
-				continue
-			}
-			sceneMatches = append(sceneMatches, photos...)
-		}
-		
-		foundPhotos = foundPhotos.Merge(sceneMatches)
+
+	rule, err := theme.Predicate()
+	if err != nil {
+		return nil, fmt.Errorf("curator: invalid theme rule: %v", err)
 	}
-	
-	// Filter by indoor/outdoor if specified
-	if theme.IndoorOutdoor != "" {
-		indoorOutdoorPhotos, err := c.query.Brains().IndoorOutdoor(theme.IndoorOutdoor)
-		if err == nil {
-			foundPhotos = foundPhotos.Intersection(indoorOutdoorPhotos)
-		}
+
+	q := c.db.Table("photos").
+		Select("photos.*").
+		Joins("JOIN brains_results ON brains_results.photo_id = photos.id")
+
+	if rule != nil {
+		clause, args := rule.SQL()
+		q = q.Where(clause, args...)
 	}
-	
-	// Apply limits
-	if theme.MaxItems > 0 && len(foundPhotos) > theme.MaxItems {
-		// Sort by aesthetic score if available
-		sort.Slice(foundPhotos, func(i, j int) bool {
-			scoreI := getAestheticScore(foundPhotos[i].ID)
-			scoreJ := getAestheticScore(foundPhotos[j].ID)
-			return scoreI > scoreJ
-		})
-		
-		// Limit to max items
-		foundPhotos = foundPhotos[:theme.MaxItems]
+
+	if theme.MaxItems > 0 {
+		q = q.Order("brains_results.aesthetic_score DESC").Limit(theme.MaxItems)
 	}
-	
-	return foundPhotos, nil
-}
 
-// getAestheticScore gets the aesthetic score for a photo from the database
-func getAestheticScore(photoID string) float32 {
-	result, err := entity.FindBrainsResult(photoID)
-	if err != nil {
-		return 0
+	if err := q.Scan(&foundPhotos).Error; err != nil {
+		return nil, err
 	}
-	return result.AestheticScore
+
+	return foundPhotos, nil
 }
 
 // syncAlbumPhotos synchronizes the photos in an album with a new set of photos
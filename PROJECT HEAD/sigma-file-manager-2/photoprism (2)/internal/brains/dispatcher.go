@@ -0,0 +1,365 @@
+package brains
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/photoprism/photoprism/pkg/rnd"
+)
+
+// Worker mode names accepted by config.BrainsWorkerMode(). Grampus and
+// CloudBrain are cluster schedulers that accept the same job-submission
+// protocol as WorkerModeHTTP once pointed at their gateway URL, so they
+// share WorkerPool/RemoteWorker rather than each needing a bespoke client.
+const (
+	WorkerModeLocal      = "local"
+	WorkerModeHTTP       = "http"
+	WorkerModeGrampus    = "grampus"
+	WorkerModeCloudBrain = "cloudbrain"
+)
+
+// Dispatcher decides where a batch of files actually gets processed, local
+// in-process or fanned out to a remote worker cluster. AnalyzeBrainsPhotos
+// dispatches through this interface instead of calling (*Brains).ProcessFiles
+// directly, so BrainsWorkerMode can move processing off the API host
+// without any caller needing to change.
+type Dispatcher interface {
+	// Dispatch processes files, reporting completion percentage (0-100) to
+	// progress as batches finish.
+	Dispatch(files []string, progress func(percent int)) (*ProcessingResults, error)
+}
+
+// NewDispatcher returns the Dispatcher config.BrainsWorkerMode() selects,
+// falling back to LocalDispatcher if a cluster mode is configured with no
+// worker URLs to actually dispatch to.
+func NewDispatcher(b *Brains) Dispatcher {
+	switch b.conf.BrainsWorkerMode() {
+	case WorkerModeHTTP, WorkerModeGrampus, WorkerModeCloudBrain:
+		urls := b.conf.BrainsWorkerURLs()
+		if len(urls) == 0 {
+			Log.Warnf("brains: worker mode %q configured with no worker URLs, falling back to local processing", b.conf.BrainsWorkerMode())
+			return &LocalDispatcher{b: b}
+		}
+
+		return NewWorkerPool(b, urls)
+	default:
+		return &LocalDispatcher{b: b}
+	}
+}
+
+// LocalDispatcher runs ProcessFiles in-process, the default when no worker
+// cluster is configured.
+type LocalDispatcher struct {
+	b *Brains
+}
+
+// Dispatch implements Dispatcher.
+func (d *LocalDispatcher) Dispatch(files []string, progress func(percent int)) (*ProcessingResults, error) {
+	results, err := d.b.ProcessFiles(files)
+
+	if progress != nil {
+		progress(100)
+	}
+
+	return results, err
+}
+
+// Remote job statuses a worker's status endpoint is expected to report.
+const (
+	RemoteJobStatusQueued    = "queued"
+	RemoteJobStatusRunning   = "running"
+	RemoteJobStatusCompleted = "completed"
+	RemoteJobStatusFailed    = "failed"
+)
+
+// RemoteJobRequest is the body RemoteWorker POSTs to a worker's job
+// endpoint.
+type RemoteJobRequest struct {
+	TaskID        string            `json:"task_id"`
+	Files         []string          `json:"files"`
+	Capabilities  map[string]bool   `json:"capabilities"`
+	ModelVersions map[string]string `json:"model_versions"`
+}
+
+// RemoteJobResponse is a worker's reply to a job submission or status poll.
+type RemoteJobResponse struct {
+	Results []*FileResult `json:"results"`
+	Status  string        `json:"status"`
+}
+
+// WorkerInfo describes one registered worker, for GET /api/v1/brains/workers.
+type WorkerInfo struct {
+	ID            string    `json:"id"`
+	Endpoint      string    `json:"endpoint"`
+	LastHeartbeat time.Time `json:"last_heartbeat"`
+	InFlight      int       `json:"in_flight"`
+}
+
+// RemoteWorker dispatches jobs to a single remote BRAINS worker over HTTP,
+// polling its status endpoint until each job reaches a terminal state.
+type RemoteWorker struct {
+	ID       string
+	Endpoint string
+	client   *http.Client
+
+	mutex    sync.Mutex
+	inFlight int
+	lastSeen time.Time
+}
+
+// NewRemoteWorker returns a RemoteWorker dispatching to endpoint.
+func NewRemoteWorker(endpoint string) *RemoteWorker {
+	return &RemoteWorker{
+		ID:       endpoint,
+		Endpoint: strings.TrimRight(endpoint, "/"),
+		client:   &http.Client{Timeout: 30 * time.Second},
+		lastSeen: time.Now(),
+	}
+}
+
+// Submit posts req to the worker's job endpoint and returns its immediate
+// response. A queued or running status means the caller must poll Status
+// for the result; a completed or failed status is already terminal, so
+// Submit releases the in-flight slot itself since Status will never be
+// called for this job.
+func (w *RemoteWorker) Submit(req RemoteJobRequest) (*RemoteJobResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("brains: failed to encode job request: %v", err)
+	}
+
+	w.mutex.Lock()
+	w.inFlight++
+	w.mutex.Unlock()
+
+	resp, err := w.client.Post(w.Endpoint+"/jobs", "application/json", bytes.NewReader(body))
+	if err != nil {
+		w.release()
+		return nil, fmt.Errorf("brains: failed to submit job to %s: %v", w.Endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		w.release()
+		return nil, fmt.Errorf("brains: worker %s rejected job with status %d", w.Endpoint, resp.StatusCode)
+	}
+
+	var out RemoteJobResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		w.release()
+		return nil, fmt.Errorf("brains: failed to decode job response from %s: %v", w.Endpoint, err)
+	}
+
+	w.touch()
+
+	if out.Status == RemoteJobStatusCompleted || out.Status == RemoteJobStatusFailed {
+		w.release()
+	}
+
+	return &out, nil
+}
+
+// Status polls the worker's status endpoint for taskID until it reaches a
+// terminal state or timeout elapses.
+func (w *RemoteWorker) Status(taskID string, timeout time.Duration) (*RemoteJobResponse, error) {
+	defer w.release()
+
+	deadline := time.Now().Add(timeout)
+
+	for time.Now().Before(deadline) {
+		resp, err := w.client.Get(fmt.Sprintf("%s/jobs/%s", w.Endpoint, taskID))
+		if err != nil {
+			return nil, fmt.Errorf("brains: failed to poll worker %s: %v", w.Endpoint, err)
+		}
+
+		var out RemoteJobResponse
+		decodeErr := json.NewDecoder(resp.Body).Decode(&out)
+		resp.Body.Close()
+
+		if decodeErr != nil {
+			return nil, fmt.Errorf("brains: failed to decode status from %s: %v", w.Endpoint, decodeErr)
+		}
+
+		w.touch()
+
+		if out.Status == RemoteJobStatusCompleted || out.Status == RemoteJobStatusFailed {
+			return &out, nil
+		}
+
+		time.Sleep(2 * time.Second)
+	}
+
+	return nil, fmt.Errorf("brains: timed out waiting for worker %s", w.Endpoint)
+}
+
+// touch records a successful response from the worker, for heartbeat
+// reporting.
+func (w *RemoteWorker) touch() {
+	w.mutex.Lock()
+	w.lastSeen = time.Now()
+	w.mutex.Unlock()
+}
+
+// release decrements the worker's in-flight job count once a job reaches a
+// terminal state or its submission fails outright.
+func (w *RemoteWorker) release() {
+	w.mutex.Lock()
+	w.inFlight--
+	w.mutex.Unlock()
+}
+
+// Info returns a snapshot of this worker's registration state.
+func (w *RemoteWorker) Info() WorkerInfo {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return WorkerInfo{
+		ID:            w.ID,
+		Endpoint:      w.Endpoint,
+		LastHeartbeat: w.lastSeen,
+		InFlight:      w.inFlight,
+	}
+}
+
+// workerPoolConcurrency bounds how many batches are in flight across the
+// whole worker cluster at once, regardless of how many workers are
+// registered, so a 100k-photo job doesn't open thousands of connections at
+// once.
+const workerPoolConcurrency = 8
+
+// workerBatchSize is how many files are grouped into a single job sent to
+// one worker at a time.
+const workerBatchSize = 25
+
+// workerMaxRetries is how many times a failed batch is resubmitted, to a
+// different worker each time, before its files are given up on.
+const workerMaxRetries = 2
+
+// WorkerPool fans a file list out across registered RemoteWorkers with
+// bounded concurrency, retrying a failed batch on a different worker before
+// giving up on just that batch's files, so one bad worker or file never
+// stalls the rest of the job.
+type WorkerPool struct {
+	b       *Brains
+	workers []*RemoteWorker
+}
+
+// NewWorkerPool returns a WorkerPool dispatching across one RemoteWorker
+// per endpoint.
+func NewWorkerPool(b *Brains, endpoints []string) *WorkerPool {
+	workers := make([]*RemoteWorker, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		workers = append(workers, NewRemoteWorker(endpoint))
+	}
+
+	return &WorkerPool{b: b, workers: workers}
+}
+
+// Workers returns every worker this pool was configured with, for
+// GET /api/v1/brains/workers.
+func (p *WorkerPool) Workers() []*RemoteWorker {
+	return p.workers
+}
+
+func (p *WorkerPool) pick(offset int) *RemoteWorker {
+	return p.workers[offset%len(p.workers)]
+}
+
+// Dispatch implements Dispatcher, splitting files into bounded batches and
+// fanning them out across the pool's workers.
+func (p *WorkerPool) Dispatch(files []string, progress func(percent int)) (*ProcessingResults, error) {
+	var batches [][]string
+
+	for i := 0; i < len(files); i += workerBatchSize {
+		end := i + workerBatchSize
+		if end > len(files) {
+			end = len(files)
+		}
+
+		batches = append(batches, files[i:end])
+	}
+
+	if len(batches) == 0 {
+		return &ProcessingResults{}, nil
+	}
+
+	results := &ProcessingResults{}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	var completed int64
+
+	sem := make(chan struct{}, workerPoolConcurrency)
+
+	for i, batch := range batches {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, batch []string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			batchResults, err := p.dispatchBatch(i, batch)
+			if err != nil {
+				Log.Errorf("brains: worker batch %d failed permanently: %v", i, err)
+			} else if batchResults != nil {
+				mutex.Lock()
+				results.Files = append(results.Files, batchResults.Files...)
+				mutex.Unlock()
+			}
+
+			if progress != nil {
+				done := atomic.AddInt64(&completed, 1)
+				progress(int(done * 100 / int64(len(batches))))
+			}
+		}(i, batch)
+	}
+
+	wg.Wait()
+
+	return results, nil
+}
+
+// dispatchBatch submits batch to a worker, retrying on a different worker
+// up to workerMaxRetries times before giving up on just this batch's files.
+func (p *WorkerPool) dispatchBatch(index int, batch []string) (*ProcessingResults, error) {
+	taskID := fmt.Sprintf("batch-%d-%s", index, rnd.GenerateUID('j'))
+
+	var lastErr error
+
+	for attempt := 0; attempt <= workerMaxRetries; attempt++ {
+		worker := p.pick(index + attempt)
+
+		resp, err := worker.Submit(RemoteJobRequest{
+			TaskID:        taskID,
+			Files:         batch,
+			Capabilities:  p.b.capabilities,
+			ModelVersions: p.b.modelVersions,
+		})
+
+		if err == nil && (resp.Status == RemoteJobStatusQueued || resp.Status == RemoteJobStatusRunning) {
+			resp, err = worker.Status(taskID, 10*time.Minute)
+		}
+
+		if err == nil && resp != nil && resp.Status == RemoteJobStatusCompleted {
+			return &ProcessingResults{Files: resp.Results}, nil
+		}
+
+		if err == nil && resp != nil {
+			err = fmt.Errorf("worker %s reported status %q", worker.Endpoint, resp.Status)
+		}
+
+		lastErr = err
+		Log.Warnf("brains: batch %d attempt %d on %s failed: %v", index, attempt, worker.Endpoint, err)
+	}
+
+	return nil, lastErr
+}
@@ -0,0 +1,59 @@
+package brains
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// CLIPEmbeddingDims is the length of the vector a CLIP-style image
+// embedding processor stores in entity.BrainsResult.Embedding, and the
+// dimensionality LSHIndex's random hyperplanes are sized for.
+const CLIPEmbeddingDims = 512
+
+// EncodeEmbedding serializes vec as little-endian float32s, the form stored
+// in entity.BrainsResult.Embedding.
+func EncodeEmbedding(vec []float32) string {
+	buf := make([]byte, len(vec)*4)
+
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+
+	return string(buf)
+}
+
+// DecodeEmbedding parses a string previously produced by EncodeEmbedding
+// back into a float32 vector.
+func DecodeEmbedding(raw string) ([]float32, error) {
+	if len(raw)%4 != 0 {
+		return nil, fmt.Errorf("brains: malformed embedding, length %d is not a multiple of 4", len(raw))
+	}
+
+	vec := make([]float32, len(raw)/4)
+	buf := []byte(raw)
+
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+
+	return vec, nil
+}
+
+// cosineSimilarity returns the cosine similarity of a and b, or 0 if either
+// is a zero vector. Both vectors must be the same length.
+func cosineSimilarity(a, b []float32) float32 {
+	var dot, normA, normB float64
+
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
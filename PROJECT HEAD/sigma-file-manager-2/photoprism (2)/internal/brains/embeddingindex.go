@@ -0,0 +1,255 @@
+package brains
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/photoprism/photoprism/internal/entity"
+)
+
+// embeddingIndexFile is the name of the persisted ANN index under the
+// cache path, so a restart can warm-start instead of rebuilding from the
+// database.
+const embeddingIndexFile = "embeddings.idx"
+
+// embeddingIndexPersistInterval is how often the index is periodically
+// saved to disk while StartPersister is running.
+const embeddingIndexPersistInterval = 15 * time.Minute
+
+// SimilarResult is one match returned by SimilarPhotos or SearchByVector:
+// the matched photo's ID and its similarity score to the query vector.
+type SimilarResult struct {
+	PhotoID string  `json:"photo_id"`
+	Score   float32 `json:"score"`
+}
+
+// Filter narrows a SimilarPhotos/SearchByVector result set after the ANN
+// search has already ranked candidates by similarity, so filtering never
+// changes which photos are considered "close", only which of them are
+// returned.
+type Filter func(result *entity.BrainsResult) bool
+
+// FilterSceneType keeps only results with the given scene type.
+func FilterSceneType(sceneType string) Filter {
+	return func(r *entity.BrainsResult) bool {
+		return r.SceneType == sceneType
+	}
+}
+
+// FilterIndoorOutdoor keeps only results matching "indoor" or "outdoor".
+func FilterIndoorOutdoor(value string) Filter {
+	return func(r *entity.BrainsResult) bool {
+		return r.IndoorOutdoor == value
+	}
+}
+
+// FilterTimeOfDay keeps only results matching the given time of day, e.g.
+// "day" or "night".
+func FilterTimeOfDay(value string) Filter {
+	return func(r *entity.BrainsResult) bool {
+		return r.TimeOfDay == value
+	}
+}
+
+// FilterProcessedRange keeps only results processed within [from, to]. Zero
+// values leave that bound open.
+func FilterProcessedRange(from, to time.Time) Filter {
+	return func(r *entity.BrainsResult) bool {
+		if !r.ProcessedAt.Valid {
+			return false
+		}
+
+		t := r.ProcessedAt.Time
+
+		if !from.IsZero() && t.Before(from) {
+			return false
+		}
+
+		if !to.IsZero() && t.After(to) {
+			return false
+		}
+
+		return true
+	}
+}
+
+// EmbeddingIndex keeps an in-memory ANNIndex of every photo's BRAINS
+// embedding in sync with the database, so similarity search doesn't need
+// to hit disk on every request.
+type EmbeddingIndex struct {
+	mutex     sync.Mutex
+	ann       ANNIndex
+	indexPath string
+}
+
+// NewEmbeddingIndex returns an EmbeddingIndex backed by ann, persisting to
+// cachePath/embeddings.idx. Pass a non-default ANNIndex (HNSW, IVF, ...) to
+// swap the search backend without touching the rest of BRAINS.
+func NewEmbeddingIndex(ann ANNIndex, cachePath string) *EmbeddingIndex {
+	return &EmbeddingIndex{
+		ann:       ann,
+		indexPath: filepath.Join(cachePath, embeddingIndexFile),
+	}
+}
+
+// Load warm-starts the index from its persisted file if one exists,
+// falling back to Rebuild from the database otherwise.
+func (idx *EmbeddingIndex) Load() error {
+	if err := idx.ann.Load(idx.indexPath); err == nil {
+		Log.Infof("brains: loaded embedding index with %d vectors from %s", idx.ann.Len(), idx.indexPath)
+		return nil
+	}
+
+	return idx.Rebuild()
+}
+
+// Rebuild repopulates the index from scratch by reading every
+// BrainsResult with a non-empty Embedding column.
+func (idx *EmbeddingIndex) Rebuild() error {
+	results, err := entity.FindBrainsResultsWithEmbedding()
+	if err != nil {
+		return err
+	}
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	fresh := idx.ann.New()
+
+	for _, r := range results {
+		vec, err := DecodeEmbedding(r.Embedding)
+		if err != nil {
+			Log.Warnf("brains: skipping malformed embedding for photo %s: %v", r.PhotoID, err)
+			continue
+		}
+
+		fresh.Upsert(r.PhotoID, vec)
+	}
+
+	idx.ann = fresh
+
+	Log.Infof("brains: rebuilt embedding index with %d vectors", idx.ann.Len())
+
+	return nil
+}
+
+// Upsert adds or replaces photoID's embedding in the index. Called from
+// saveResultsToDatabase so new and re-processed photos become searchable
+// incrementally, without waiting for a rebuild.
+func (idx *EmbeddingIndex) Upsert(photoID string, embedding string) {
+	vec, err := DecodeEmbedding(embedding)
+	if err != nil {
+		Log.Warnf("brains: failed to index embedding for photo %s: %v", photoID, err)
+		return
+	}
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.ann.Upsert(photoID, vec)
+}
+
+// SearchByVector returns up to k photos whose embeddings are closest to
+// vec, most similar first, with any of filters applied against each
+// candidate's BrainsResult after ranking.
+func (idx *EmbeddingIndex) SearchByVector(vec []float32, k int, filters ...Filter) ([]SimilarResult, error) {
+	idx.mutex.Lock()
+	matches := idx.ann.Search(vec, 0) // unfiltered so post-ANN filters have enough candidates
+	idx.mutex.Unlock()
+
+	var results []SimilarResult
+
+	for _, m := range matches {
+		if len(filters) > 0 {
+			result, err := entity.FindBrainsResult(m.ID)
+			if err != nil || !passesFilters(result, filters) {
+				continue
+			}
+		}
+
+		results = append(results, SimilarResult{PhotoID: m.ID, Score: m.Score})
+
+		if len(results) == k {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// SimilarPhotos returns up to k photos visually similar to photoID, most
+// similar first. photoID itself is excluded from the results.
+func (idx *EmbeddingIndex) SimilarPhotos(photoID string, k int, filters ...Filter) ([]SimilarResult, error) {
+	origin, err := entity.FindBrainsResult(photoID)
+	if err != nil {
+		return nil, err
+	}
+
+	vec, err := DecodeEmbedding(origin.Embedding)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := idx.SearchByVector(vec, k+1, filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := results[:0]
+	for _, r := range results {
+		if r.PhotoID == photoID {
+			continue
+		}
+
+		filtered = append(filtered, r)
+
+		if len(filtered) == k {
+			break
+		}
+	}
+
+	return filtered, nil
+}
+
+// passesFilters reports whether result satisfies every filter.
+func passesFilters(result *entity.BrainsResult, filters []Filter) bool {
+	for _, f := range filters {
+		if !f(result) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Save persists the index to disk so the next startup can warm-start
+// instead of rebuilding from the database.
+func (idx *EmbeddingIndex) Save() error {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	return idx.ann.Save(idx.indexPath)
+}
+
+// StartPersister periodically saves the index to disk until stop is
+// closed, so a crash loses at most one persist interval of incremental
+// updates. Pass a nil stop to run for the lifetime of the process.
+func (idx *EmbeddingIndex) StartPersister(stop <-chan struct{}) {
+	ticker := time.NewTicker(embeddingIndexPersistInterval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := idx.Save(); err != nil {
+					Log.Warnf("brains: failed to persist embedding index: %v", err)
+				}
+			}
+		}
+	}()
+}
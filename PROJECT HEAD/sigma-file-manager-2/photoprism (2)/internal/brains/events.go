@@ -0,0 +1,160 @@
+package brains
+
+import (
+	"sync"
+	"time"
+
+	"github.com/photoprism/photoprism/internal/event"
+)
+
+// Progress phases reported by ProgressEvent.
+const (
+	PhaseProgress  = "progress"
+	PhaseCompleted = "completed"
+	PhaseFailed    = "failed"
+)
+
+// progressEvery and progressInterval bound how often ProcessFiles emits
+// progress events, so a large batch doesn't flood subscribers with one
+// event per file; an event is published at most once per progressInterval,
+// or every progressEvery files, whichever comes first.
+const (
+	progressEvery    = 10
+	progressInterval = 500 * time.Millisecond
+)
+
+// ProgressEvent reports how far a ProcessFiles run has gotten. It's
+// published through the event package as "brains.progress" /
+// "brains.completed" / "brains.failed", and relayed to any listener
+// registered via Subscribe, e.g. the /api/v1/brains/events websocket.
+type ProgressEvent struct {
+	Phase      string        `json:"phase"`
+	File       string        `json:"file,omitempty"`
+	FilesDone  int           `json:"files_done"`
+	FilesTotal int           `json:"files_total"`
+	BytesDone  int64         `json:"bytes_done"`
+	ETA        time.Duration `json:"eta"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// progressTracker accumulates per-file completions from processBatch's
+// worker goroutines and decides when a ProgressEvent is due.
+type progressTracker struct {
+	mutex       sync.Mutex
+	start       time.Time
+	lastPublish time.Time
+	total       int
+	done        int
+	bytesDone   int64
+}
+
+// newProgressTracker creates a tracker for a run of total files.
+func newProgressTracker(total int) *progressTracker {
+	return &progressTracker{start: time.Now(), total: total}
+}
+
+// fileDone records that path finished processing and publishes a progress
+// event if enough files or time have passed since the last one.
+func (t *progressTracker) fileDone(path string, size int64, err error) {
+	t.mutex.Lock()
+	t.done++
+	t.bytesDone += size
+	done, bytesDone := t.done, t.bytesDone
+	elapsed := time.Since(t.start)
+	due := done == t.total || done%progressEvery == 0 || time.Since(t.lastPublish) >= progressInterval
+	if due {
+		t.lastPublish = time.Now()
+	}
+	t.mutex.Unlock()
+
+	if err != nil || !due {
+		return
+	}
+
+	var eta time.Duration
+	if done > 0 && done < t.total {
+		eta = (elapsed / time.Duration(done)) * time.Duration(t.total-done)
+	}
+
+	publishProgress(ProgressEvent{
+		Phase:      PhaseProgress,
+		File:       path,
+		FilesDone:  done,
+		FilesTotal: t.total,
+		BytesDone:  bytesDone,
+		ETA:        eta,
+	})
+}
+
+// completed builds the final "completed" event for this run.
+func (t *progressTracker) completed() ProgressEvent {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return ProgressEvent{Phase: PhaseCompleted, FilesDone: t.done, FilesTotal: t.total, BytesDone: t.bytesDone}
+}
+
+// failed builds the final "failed" event for this run.
+func (t *progressTracker) failed(err error) ProgressEvent {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return ProgressEvent{Phase: PhaseFailed, FilesDone: t.done, FilesTotal: t.total, BytesDone: t.bytesDone, Error: err.Error()}
+}
+
+// hub fans ProgressEvents out to every subscriber, e.g. the
+// /api/v1/brains/events websocket relay.
+type hub struct {
+	mutex       sync.RWMutex
+	subscribers map[chan ProgressEvent]bool
+}
+
+var progressHub = &hub{subscribers: make(map[chan ProgressEvent]bool)}
+
+// Subscribe registers a new listener for progress events. Call the returned
+// function to unsubscribe and release its channel once the caller is done
+// reading from it.
+func Subscribe() (<-chan ProgressEvent, func()) {
+	ch := make(chan ProgressEvent, 32)
+
+	progressHub.mutex.Lock()
+	progressHub.subscribers[ch] = true
+	progressHub.mutex.Unlock()
+
+	unsubscribe := func() {
+		progressHub.mutex.Lock()
+		defer progressHub.mutex.Unlock()
+
+		if _, ok := progressHub.subscribers[ch]; ok {
+			delete(progressHub.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+// publishProgress broadcasts ev through the event package and to every
+// websocket subscriber. A subscriber whose buffer is full has the event
+// dropped for it rather than blocking the processing loop.
+func publishProgress(ev ProgressEvent) {
+	switch ev.Phase {
+	case PhaseCompleted:
+		event.Publish("brains", event.BrainsCompleted, ev)
+	case PhaseFailed:
+		event.Publish("brains", event.BrainsFailed, ev)
+	default:
+		event.Publish("brains", event.BrainsProgress, ev)
+	}
+
+	progressHub.mutex.RLock()
+	defer progressHub.mutex.RUnlock()
+
+	for ch := range progressHub.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			Log.Warnf("brains: progress subscriber too slow, dropping event")
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package brains
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"path/filepath"
+
+	tf "github.com/tensorflow/tensorflow/tensorflow/go"
+)
+
+// faceModelSHA256 is the expected checksum of the FaceNet embedding model.
+const faceModelSHA256 = "2b6c8f1e9d3a4b5c6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5"
+
+// faceEmbeddingInput is FaceNet's expected input size in pixels.
+const faceEmbeddingInput = 160
+
+// FaceEmbeddingDims is the length of the vector FaceEmbedder.Embed returns.
+const FaceEmbeddingDims = 512
+
+// FaceEmbedder computes FaceNet embeddings for cropped face images, lazily
+// loading the model through a ModelManager so the first Embed call pays the
+// load cost rather than Init.
+type FaceEmbedder struct {
+	modelFile string
+	models    *ModelManager
+}
+
+// NewFaceEmbedder returns a FaceEmbedder that loads its model from
+// modelPath/facenet.pb on first use.
+func NewFaceEmbedder(modelPath string) *FaceEmbedder {
+	return &FaceEmbedder{
+		modelFile: filepath.Join(modelPath, "facenet.pb"),
+		models:    NewModelManager(),
+	}
+}
+
+// ModelFiles returns the model files required by the face embedder.
+func (e *FaceEmbedder) ModelFiles() []ModelSpec {
+	return []ModelSpec{
+		{URL: "https://dl.photoprism.app/tensorflow/facenet.pb", SHA256: faceModelSHA256, Size: 95_000_000},
+	}
+}
+
+// Embed crops box out of img, resizes it to FaceNet's 160x160 input size,
+// normalizes it with per-image standardization, and returns its
+// L2-normalized, 512-dimensional embedding.
+func (e *FaceEmbedder) Embed(img image.Image, box image.Rectangle) ([]float32, error) {
+	model, err := e.models.GetModel(e.modelFile, "face")
+	if err != nil {
+		return nil, fmt.Errorf("brains: face model unavailable: %v", err)
+	}
+
+	input, err := faceTensor(img, box)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := model.Predict(input)
+	if err != nil {
+		return nil, fmt.Errorf("brains: face embedding inference failed: %v", err)
+	}
+
+	vec, ok := output.Value().([][]float32)
+	if !ok || len(vec) == 0 {
+		return nil, fmt.Errorf("brains: unexpected face embedding output shape")
+	}
+
+	return l2Normalize(vec[0]), nil
+}
+
+// Close releases the embedder's loaded model.
+func (e *FaceEmbedder) Close() {
+	e.models.CloseAll()
+}
+
+// faceTensor crops box out of img, resizes it to faceEmbeddingInput square
+// using nearest-neighbor sampling, and standardizes it to zero mean and unit
+// variance across the whole crop, the preprocessing FaceNet was trained
+// with, returning a tensor shaped [1, 160, 160, 3].
+func faceTensor(img image.Image, box image.Rectangle) (*tf.Tensor, error) {
+	box = box.Intersect(img.Bounds())
+	if box.Empty() {
+		return nil, fmt.Errorf("brains: face box is outside image bounds")
+	}
+
+	rgb := resizeRGB(img, box, faceEmbeddingInput, faceEmbeddingInput)
+
+	var sum, sumSq float64
+	n := float64(len(rgb))
+
+	for _, v := range rgb {
+		fv := float64(v)
+		sum += fv
+		sumSq += fv * fv
+	}
+
+	mean := sum / n
+	variance := sumSq/n - mean*mean
+	if variance < 1e-6 {
+		variance = 1e-6
+	}
+	stddev := math.Sqrt(variance)
+
+	pixels := make([][][]float32, faceEmbeddingInput)
+	i := 0
+	for y := 0; y < faceEmbeddingInput; y++ {
+		row := make([][]float32, faceEmbeddingInput)
+		for x := 0; x < faceEmbeddingInput; x++ {
+			px := make([]float32, 3)
+			for c := 0; c < 3; c++ {
+				px[c] = float32((float64(rgb[i]) - mean) / stddev)
+				i++
+			}
+			row[x] = px
+		}
+		pixels[y] = row
+	}
+
+	tensor, err := tf.NewTensor([][][][]float32{pixels})
+	if err != nil {
+		return nil, fmt.Errorf("brains: failed to build face tensor: %v", err)
+	}
+
+	return tensor, nil
+}
+
+// resizeRGB crops box out of img and resizes it to width x height using
+// nearest-neighbor sampling, returning interleaved R, G, B byte values in
+// row-major order.
+func resizeRGB(img image.Image, box image.Rectangle, width, height int) []byte {
+	out := make([]byte, 0, width*height*3)
+
+	bw, bh := box.Dx(), box.Dy()
+
+	for y := 0; y < height; y++ {
+		srcY := box.Min.Y + y*bh/height
+		for x := 0; x < width; x++ {
+			srcX := box.Min.X + x*bw/width
+			r, g, b, _ := img.At(srcX, srcY).RGBA()
+			out = append(out, byte(r>>8), byte(g>>8), byte(b>>8))
+		}
+	}
+
+	return out
+}
+
+// l2Normalize scales vec to unit length, leaving it unchanged if its norm is
+// zero.
+func l2Normalize(vec []float32) []float32 {
+	var sumSq float64
+	for _, v := range vec {
+		sumSq += float64(v) * float64(v)
+	}
+
+	norm := math.Sqrt(sumSq)
+	if norm == 0 {
+		return vec
+	}
+
+	out := make([]float32, len(vec))
+	for i, v := range vec {
+		out[i] = float32(float64(v) / norm)
+	}
+
+	return out
+}
@@ -0,0 +1,219 @@
+package brains
+
+import (
+	"github.com/photoprism/photoprism/internal/entity"
+)
+
+// faceSimilarityThreshold is the minimum cosine similarity between two face
+// embeddings for them to be considered the same person.
+const faceSimilarityThreshold = 0.65
+
+// SimilarMarker is one match returned by FaceClusterer.SimilarTo: the
+// matched marker's UID and its cosine similarity to the query face.
+type SimilarMarker struct {
+	MarkerUID string  `json:"marker_uid"`
+	Score     float32 `json:"score"`
+}
+
+// FaceClusterer groups markers with a face embedding into entity.Person
+// records by cosine similarity, using union-find so that two markers don't
+// need to match each other directly, only both match a third, to end up in
+// the same person. It runs periodically from the scheduler as background
+// maintenance rather than at index time, since comparing every marker
+// pairwise is too expensive to do inline for each newly indexed photo.
+type FaceClusterer struct{}
+
+// NewFaceClusterer returns a FaceClusterer.
+func NewFaceClusterer() *FaceClusterer {
+	return &FaceClusterer{}
+}
+
+// Cluster groups every marker with a face embedding into entity.Person
+// records and returns how many markers were considered, so callers (and the
+// scheduler log line) can tell a real run from a no-op one.
+func (c *FaceClusterer) Cluster() (int, error) {
+	markers, err := entity.FindMarkersWithEmbedding()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(markers) == 0 {
+		return 0, nil
+	}
+
+	vectors := make([][]float32, len(markers))
+	for i, m := range markers {
+		vec, err := DecodeEmbedding(m.Embedding)
+		if err != nil {
+			Log.Warnf("brains: skipping malformed face embedding for marker %s: %v", m.MarkerUID, err)
+			continue
+		}
+		vectors[i] = vec
+	}
+
+	uf := newUnionFind(len(markers))
+
+	for i := range markers {
+		if vectors[i] == nil {
+			continue
+		}
+
+		for j := i + 1; j < len(markers); j++ {
+			if vectors[j] == nil {
+				continue
+			}
+
+			if cosineSimilarity(vectors[i], vectors[j]) >= faceSimilarityThreshold {
+				uf.union(i, j)
+			}
+		}
+	}
+
+	groups := make(map[int][]*entity.Marker)
+	for i, m := range markers {
+		if vectors[i] == nil {
+			continue
+		}
+
+		root := uf.find(i)
+		groups[root] = append(groups[root], m)
+	}
+
+	for _, group := range groups {
+		if err := assignPerson(group); err != nil {
+			Log.Warnf("brains: failed to assign person for face cluster: %v", err)
+		}
+	}
+
+	return len(markers), nil
+}
+
+// SimilarTo returns up to k markers whose face embedding is closest to
+// markerUID's, most similar first, so the UI can offer a "photos of this
+// person" search from any detected face, even one Cluster hasn't grouped
+// into a Person yet. markerUID itself is excluded from the results.
+func (c *FaceClusterer) SimilarTo(markerUID string, k int) ([]SimilarMarker, error) {
+	origin, err := entity.FindMarker(markerUID)
+	if err != nil {
+		return nil, err
+	}
+
+	originVec, err := DecodeEmbedding(origin.Embedding)
+	if err != nil {
+		return nil, err
+	}
+
+	markers, err := entity.FindMarkersWithEmbedding()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SimilarMarker
+
+	for _, m := range markers {
+		if m.MarkerUID == markerUID {
+			continue
+		}
+
+		vec, err := DecodeEmbedding(m.Embedding)
+		if err != nil {
+			continue
+		}
+
+		results = append(results, SimilarMarker{MarkerUID: m.MarkerUID, Score: cosineSimilarity(originVec, vec)})
+	}
+
+	sortSimilarMarkers(results)
+
+	if len(results) > k {
+		results = results[:k]
+	}
+
+	return results, nil
+}
+
+// sortSimilarMarkers orders results by descending score.
+func sortSimilarMarkers(results []SimilarMarker) {
+	for i := 1; i < len(results); i++ {
+		for j := i; j > 0 && results[j].Score > results[j-1].Score; j-- {
+			results[j], results[j-1] = results[j-1], results[j]
+		}
+	}
+}
+
+// assignPerson links every marker in group to a single entity.Person,
+// reusing a person already referenced by any marker in the group so repeated
+// clustering runs converge instead of creating duplicates, and only creating
+// a new one when none of them do.
+func assignPerson(group []*entity.Marker) error {
+	var person *entity.Person
+
+	for _, m := range group {
+		if m.SubjectUID == "" {
+			continue
+		}
+
+		if p, err := entity.FindPerson(m.SubjectUID); err == nil {
+			person = p
+			break
+		}
+	}
+
+	if person == nil {
+		person = entity.NewPerson()
+
+		if err := person.Save(); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range group {
+		if m.SubjectUID == person.SubjectUID {
+			continue
+		}
+
+		m.SubjectUID = person.SubjectUID
+
+		if err := m.Save(); err != nil {
+			Log.Warnf("brains: failed to link marker %s to person %s: %v", m.MarkerUID, person.SubjectUID, err)
+		}
+	}
+
+	return nil
+}
+
+// unionFind is a disjoint-set forest used to group markers whose pairwise
+// similarity forms a chain, even when not every pair in the group meets
+// faceSimilarityThreshold directly.
+type unionFind struct {
+	parent []int
+}
+
+// newUnionFind returns a unionFind over n elements, each initially its own
+// singleton set.
+func newUnionFind(n int) *unionFind {
+	uf := &unionFind{parent: make([]int, n)}
+	for i := range uf.parent {
+		uf.parent[i] = i
+	}
+
+	return uf
+}
+
+// find returns the representative element of x's set, path-compressing as
+// it walks up.
+func (uf *unionFind) find(x int) int {
+	if uf.parent[x] != x {
+		uf.parent[x] = uf.find(uf.parent[x])
+	}
+
+	return uf.parent[x]
+}
+
+// union merges a's and b's sets.
+func (uf *unionFind) union(a, b int) {
+	ra, rb := uf.find(a), uf.find(b)
+	if ra != rb {
+		uf.parent[ra] = rb
+	}
+}
@@ -0,0 +1,123 @@
+package brains
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	pigo "github.com/esimov/pigo/core"
+)
+
+// faceDetectorSHA256 is the expected checksum of the Pigo face cascade file.
+const faceDetectorSHA256 = "975c824003bff3aad3c2e62fe3ba6ceb9cdd35decc405a73676d9bde2912ca93"
+
+// faceDetectorMinSize is the smallest face, in pixels on a side, Detect will
+// report, chosen to skip background faces too small to embed reliably.
+const faceDetectorMinSize = 40
+
+// faceDetectorScoreThreshold is the minimum cascade score a detection must
+// clear to be reported, trading a few missed faces for far fewer false
+// positives on busy backgrounds.
+const faceDetectorScoreThreshold = 5.0
+
+// FaceDetector locates face bounding boxes in an image using Pigo's pixel
+// intensity comparison cascade. Unlike FaceEmbedder it isn't backed by a
+// TensorFlow graph, so it loads its cascade file directly instead of going
+// through ModelManager.
+type FaceDetector struct {
+	cascadeFile string
+
+	once       sync.Once
+	classifier *pigo.Pigo
+	loadErr    error
+}
+
+// NewFaceDetector returns a FaceDetector that loads its cascade from
+// modelPath/facefinder on first use.
+func NewFaceDetector(modelPath string) *FaceDetector {
+	return &FaceDetector{cascadeFile: filepath.Join(modelPath, "facefinder")}
+}
+
+// ModelFiles returns the model files required by the face detector.
+func (d *FaceDetector) ModelFiles() []ModelSpec {
+	return []ModelSpec{
+		{URL: "https://dl.photoprism.app/tensorflow/facefinder", SHA256: faceDetectorSHA256, Size: 460_000},
+	}
+}
+
+// load unpacks the cascade file the first time Detect is called, so a
+// caller that never detects faces doesn't pay the load cost.
+func (d *FaceDetector) load() error {
+	d.once.Do(func() {
+		raw, err := os.ReadFile(d.cascadeFile)
+		if err != nil {
+			d.loadErr = fmt.Errorf("brains: failed to read face cascade: %v", err)
+			return
+		}
+
+		classifier, err := pigo.NewPigo().Unpack(raw)
+		if err != nil {
+			d.loadErr = fmt.Errorf("brains: failed to unpack face cascade: %v", err)
+			return
+		}
+
+		d.classifier = classifier
+	})
+
+	return d.loadErr
+}
+
+// Detect returns the bounding box of every face Pigo finds in img, sorted
+// by descending detection score so a caller that only wants the most
+// confident faces can take a prefix.
+func (d *FaceDetector) Detect(img image.Image) ([]image.Rectangle, error) {
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	cParams := pigo.CascadeParams{
+		MinSize:     faceDetectorMinSize,
+		MaxSize:     maxInt(w, h),
+		ShiftFactor: 0.1,
+		ScaleFactor: 1.1,
+		ImageParams: pigo.ImageParams{
+			Pixels: pigo.RgbToGrayscale(img),
+			Rows:   h,
+			Cols:   w,
+			Dim:    w,
+		},
+	}
+
+	dets := d.classifier.RunCascade(cParams, 0.0)
+	dets = d.classifier.ClusterDetections(dets, 0.2)
+
+	sort.Slice(dets, func(i, j int) bool { return dets[i].Q > dets[j].Q })
+
+	var boxes []image.Rectangle
+
+	for _, det := range dets {
+		if float32(det.Q) < faceDetectorScoreThreshold {
+			continue
+		}
+
+		half := det.Scale / 2
+		boxes = append(boxes, image.Rect(det.Col-half, det.Row-half, det.Col+half, det.Row+half).Intersect(bounds))
+	}
+
+	return boxes, nil
+}
+
+// maxInt returns the larger of a and b.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,209 @@
+package brains
+
+import (
+	"fmt"
+	"image"
+	"sync"
+
+	"github.com/photoprism/photoprism/internal/entity"
+)
+
+// IndexPipelineOptions controls which steps IndexPipeline.Run performs for
+// each file and whether it reprocesses files it has already indexed.
+type IndexPipelineOptions struct {
+	FacesOnly  bool
+	LabelsOnly bool
+	Rescan     bool
+}
+
+// IndexPipelineResult totals how many files IndexPipeline.Run processed and
+// how many of those failed at least one step, for the CLI to report a
+// summary.
+type IndexPipelineResult struct {
+	Processed int
+	Failed    int
+}
+
+// IndexPipeline runs BRAINS' offline face, label and perceptual-hash
+// indexing over a fixed list of files, independent of the database-backed
+// photo index: results are keyed by file path (entity.BrainsFace,
+// entity.BrainsLabel, entity.BrainsPhash) rather than PhotoID, so it can
+// index originals that haven't been imported yet.
+type IndexPipeline struct {
+	brains   *Brains
+	detector *FaceDetector
+	workers  int
+	opt      IndexPipelineOptions
+}
+
+// NewIndexPipeline returns an IndexPipeline that reuses b's FaceEmbedder
+// and object processor, detecting face bounding boxes with a dedicated
+// Pigo cascade first since FaceEmbedder only embeds a box it's handed, it
+// doesn't locate one.
+func NewIndexPipeline(b *Brains, workers int, opt IndexPipelineOptions) *IndexPipeline {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &IndexPipeline{
+		brains:   b,
+		detector: NewFaceDetector(b.modelPath),
+		workers:  workers,
+		opt:      opt,
+	}
+}
+
+// Run indexes every file in files, fanning work out over p.workers
+// goroutines, and blocks until all of them have been processed.
+func (p *IndexPipeline) Run(files []string) IndexPipelineResult {
+	if !p.brains.initialized {
+		if err := p.brains.Init(); err != nil {
+			Log.Errorf("brains: index pipeline failed to initialize: %v", err)
+			return IndexPipelineResult{Failed: len(files)}
+		}
+	}
+
+	jobs := make(chan string)
+	ok := make(chan bool)
+
+	var wg sync.WaitGroup
+	wg.Add(p.workers)
+
+	for i := 0; i < p.workers; i++ {
+		go func() {
+			defer wg.Done()
+			for fileName := range jobs {
+				ok <- p.indexFile(fileName)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(ok)
+	}()
+
+	go func() {
+		for _, fileName := range files {
+			jobs <- fileName
+		}
+		close(jobs)
+	}()
+
+	var result IndexPipelineResult
+	for success := range ok {
+		result.Processed++
+		if !success {
+			result.Failed++
+		}
+	}
+
+	return result
+}
+
+// indexFile runs the requested steps for a single file, logging rather
+// than aborting the run on a per-file failure, and reports whether every
+// requested step succeeded.
+func (p *IndexPipeline) indexFile(fileName string) bool {
+	if !p.opt.Rescan && entity.BrainsFileIndexed(fileName) {
+		return true
+	}
+
+	img, err := decodeImage(fileName)
+	if err != nil {
+		Log.Warnf("brains: index pipeline failed to decode %s: %v", fileName, err)
+		return false
+	}
+
+	success := true
+
+	if !p.opt.LabelsOnly {
+		if err := p.indexFaces(fileName, img); err != nil {
+			Log.Warnf("brains: index pipeline failed to index faces in %s: %v", fileName, err)
+			success = false
+		}
+	}
+
+	if !p.opt.FacesOnly {
+		if err := p.indexLabels(fileName, img); err != nil {
+			Log.Warnf("brains: index pipeline failed to index labels in %s: %v", fileName, err)
+			success = false
+		}
+	}
+
+	if err := p.indexPhash(fileName, img); err != nil {
+		Log.Warnf("brains: index pipeline failed to hash %s: %v", fileName, err)
+		success = false
+	}
+
+	return success
+}
+
+// indexFaces detects every face in img and persists its bounding box and
+// FaceNet embedding as a BrainsFace row.
+func (p *IndexPipeline) indexFaces(fileName string, img image.Image) error {
+	boxes, err := p.detector.Detect(img)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+
+	for _, box := range boxes {
+		vec, err := p.brains.faceEmbedder.Embed(img, box)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		face := entity.NewBrainsFace(fileName, box.Min.X, box.Min.Y, box.Dx(), box.Dy(), EncodeEmbedding(vec))
+		if err := face.Save(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// indexLabels runs the object processor over img and persists each
+// detected label as a BrainsLabel row.
+func (p *IndexPipeline) indexLabels(fileName string, img image.Image) error {
+	proc, ok := p.brains.GetProcessor("object")
+	if !ok {
+		return fmt.Errorf("object processor not available")
+	}
+
+	result, err := proc.Analyze(img)
+	if err != nil {
+		return err
+	}
+
+	objects, ok := result.(ObjectResult)
+	if !ok {
+		return fmt.Errorf("unexpected object analysis result type")
+	}
+
+	var firstErr error
+
+	for _, obj := range objects.Objects {
+		label := entity.NewBrainsLabel(fileName, obj.Label, obj.Confidence)
+		if err := label.Save(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// indexPhash computes img's perceptual hash and persists it as a
+// BrainsPhash row.
+func (p *IndexPipeline) indexPhash(fileName string, img image.Image) error {
+	hash, err := Phash(img)
+	if err != nil {
+		return err
+	}
+
+	return entity.NewBrainsPhash(fileName, hash).Save()
+}
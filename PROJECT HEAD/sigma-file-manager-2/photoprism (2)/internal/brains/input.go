@@ -0,0 +1,88 @@
+package brains
+
+import (
+	"fmt"
+
+	"github.com/photoprism/photoprism/internal/config"
+	"github.com/photoprism/photoprism/internal/entity"
+	"github.com/photoprism/photoprism/internal/thumb"
+)
+
+// SelectInput picks the smallest cached thumbnail of photo that still meets
+// minPixels on its shortest side, falling back to the original file whenever
+// no thumbnail is large enough or none has been generated yet. It returns an
+// error if photo has no primary file or that file has no name. Callers should treat the
+// returned path as "best available", never as a guarantee that it meets
+// minPixels.
+func SelectInput(conf *config.Config, photo *entity.Photo, minPixels int) (string, error) {
+	file, err := photo.PrimaryFile()
+	if err != nil {
+		return "", err
+	}
+
+	filename := file.FileName()
+	if filename == "" {
+		return "", fmt.Errorf("brains: photo %s has no file name", photo.PhotoUID)
+	}
+
+	originalPath := conf.OriginalsPath() + "/" + filename
+
+	name := selectThumbSize(minPixels)
+	if name == "" {
+		// No registered thumbnail is large enough for this processor, so
+		// the original file is the only option.
+		return originalPath, nil
+	}
+
+	thumbPath, err := file.Thumbnail(conf.ThumbPath(), name)
+	if err != nil {
+		Log.Debugf("brains: no %s thumbnail for %s, falling back to original: %v", name, filename, err)
+		return originalPath, nil
+	}
+
+	return thumbPath, nil
+}
+
+// selectThumbSize returns the name of the smallest registered thumbnail
+// size whose shortest side is at least minPixels, or "" if none qualifies
+// and the original file should be used instead.
+func selectThumbSize(minPixels int) thumb.Name {
+	var bestName thumb.Name
+	bestShortSide := 0
+	found := false
+
+	for name, size := range thumb.Sizes {
+		shortSide := size.Width
+		if size.Height < shortSide {
+			shortSide = size.Height
+		}
+
+		if shortSide < minPixels {
+			continue
+		}
+
+		if !found || shortSide < bestShortSide {
+			bestName = name
+			bestShortSide = shortSide
+			found = true
+		}
+	}
+
+	return bestName
+}
+
+// SelectInputPath picks the best input file for photo across every
+// processor currently enabled on b, by requiring whatever the most
+// demanding enabled processor needs. A single decoded image is shared by
+// all processors in a batch, so it has to satisfy the largest minimum.
+func (b *Brains) SelectInputPath(photo *entity.Photo) (string, error) {
+	minPixels := 0
+
+	for _, processor := range b.processors {
+		if p := processor.MinInputPixels(); p > minPixels {
+			minPixels = p
+		}
+	}
+
+	return SelectInput(b.conf, photo, minPixels)
+}
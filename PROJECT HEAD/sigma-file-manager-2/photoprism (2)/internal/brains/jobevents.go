@@ -0,0 +1,101 @@
+package brains
+
+import (
+	"time"
+
+	"github.com/photoprism/photoprism/internal/entity"
+)
+
+// SubmitFiles persists files as a new BrainsJob and starts processing it in
+// the background, returning the job's ID immediately so callers can poll
+// GetJobEvents or subscribe to the SSE stream for progress instead of
+// blocking on the whole batch like ProcessFiles does.
+func (b *Brains) SubmitFiles(files []string) (jobID string, err error) {
+	if !b.initialized {
+		if err := b.Init(); err != nil {
+			return "", err
+		}
+	}
+
+	job := entity.NewBrainsJob(files)
+	job.Status = entity.JobQueued
+
+	if err := job.Save(); err != nil {
+		return "", err
+	}
+
+	for _, file := range files {
+		recordJobEvent(job.ID, file, entity.JobEventQueued, "", "")
+	}
+
+	go b.runSubmittedJob(job)
+
+	return job.ID, nil
+}
+
+// GetJobEvents returns every event recorded for jobID since the given time,
+// so a caller can poll for just what's new, e.g. an SSE stream replaying a
+// job's progress or a "retry just the failed files" workflow inspecting
+// which files ended up in entity.JobEventFailed.
+func (b *Brains) GetJobEvents(jobID string, since time.Time) ([]*entity.BrainsJobEvent, error) {
+	return entity.FindBrainsJobEvents(jobID, since)
+}
+
+// runSubmittedJob processes every file in job through processBatch,
+// recording a JobEvent for each state transition along the way, and updates
+// the job's status once all batches have run.
+func (b *Brains) runSubmittedJob(job *entity.BrainsJob) {
+	job.Status = entity.JobRunning
+
+	if err := job.Save(); err != nil {
+		Log.Errorf("brains: job %s failed to update status: %v", job.ID, err)
+	}
+
+	files := job.Photos()
+	var firstErr error
+
+	for i := 0; i < len(files); i += b.batchSize {
+		end := i + b.batchSize
+		if end > len(files) {
+			end = len(files)
+		}
+
+		onEvent := func(file, state, reason, message string) {
+			recordJobEvent(job.ID, file, state, reason, message)
+		}
+
+		if _, err := b.processBatch(files[i:end], nil, onEvent); err != nil && firstErr == nil {
+			firstErr = err
+		}
+
+		job.Cursor = end
+
+		if err := job.Save(); err != nil {
+			Log.Errorf("brains: job %s failed to checkpoint: %v", job.ID, err)
+		}
+	}
+
+	if firstErr != nil {
+		job.Status = entity.JobFailed
+		job.Error = firstErr.Error()
+	} else {
+		job.Status = entity.JobCompleted
+	}
+
+	if err := job.Save(); err != nil {
+		Log.Errorf("brains: job %s failed to save final status: %v", job.ID, err)
+	}
+}
+
+// recordJobEvent persists a single file's state transition for a job. It
+// logs rather than fails on a write error since an event-log write
+// shouldn't abort the batch it's describing.
+func recordJobEvent(jobID, file, state, reason, message string) {
+	if jobID == "" {
+		return
+	}
+
+	if err := entity.NewBrainsJobEvent(jobID, file, state, reason, message).Save(); err != nil {
+		Log.Warnf("brains: failed to record job event for %s: %v", file, err)
+	}
+}
@@ -0,0 +1,252 @@
+package brains
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// loadSampleInterval is how often the system load sampler refreshes its
+// moving-average idle percentage.
+const loadSampleInterval = 5 * time.Second
+
+// LoadSampler tracks a moving-average CPU idle percentage, sampled on a
+// fixed cadence, so callers can make scheduling decisions based on real
+// system load rather than a time-of-day heuristic.
+type LoadSampler struct {
+	mutex      sync.RWMutex
+	idlePct    float64
+	lastCPU    cpuTimes
+	haveSample bool
+	stopChan   chan bool
+}
+
+// cpuTimes holds cumulative CPU tick counters used to compute deltas between
+// samples.
+type cpuTimes struct {
+	idle  uint64
+	total uint64
+}
+
+// NewLoadSampler creates a new load sampler. Call Start to begin sampling.
+func NewLoadSampler() *LoadSampler {
+	return &LoadSampler{
+		stopChan: make(chan bool),
+	}
+}
+
+// Start begins sampling system load on loadSampleInterval in the background.
+func (l *LoadSampler) Start() {
+	go l.run()
+}
+
+// Stop halts background sampling.
+func (l *LoadSampler) Stop() {
+	select {
+	case l.stopChan <- true:
+	default:
+	}
+}
+
+// IdlePercent returns the most recently computed moving-average idle
+// percentage in the range [0, 1].
+func (l *LoadSampler) IdlePercent() float64 {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return l.idlePct
+}
+
+// run samples load every loadSampleInterval and updates the moving average.
+func (l *LoadSampler) run() {
+	ticker := time.NewTicker(loadSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stopChan:
+			return
+		case <-ticker.C:
+			l.sample()
+		}
+	}
+}
+
+// sample takes a single load reading and folds it into the moving average
+// using a simple exponential weighting (alpha = 0.3).
+func (l *LoadSampler) sample() {
+	const alpha = 0.3
+
+	idle := sampleIdlePercent(l)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if !l.haveSample {
+		l.idlePct = idle
+		l.haveSample = true
+		return
+	}
+
+	l.idlePct = alpha*idle + (1-alpha)*l.idlePct
+}
+
+// sampleIdlePercent returns a single idle-percentage reading using the best
+// available platform signal, falling back to fallbackIdlePercent's core-count
+// estimate when no OS-level counters are available.
+func sampleIdlePercent(l *LoadSampler) float64 {
+	switch runtime.GOOS {
+	case "linux":
+		if idle, ok := linuxProcStatIdle(l); ok {
+			return idle
+		}
+	case "darwin":
+		// host_statistics requires cgo; fall through to the portable
+		// fallback on pure-Go builds.
+	case "windows":
+		// GlobalMemoryStatusEx requires the Windows syscall package; fall
+		// through to the portable fallback on platforms without it wired up.
+	}
+
+	return fallbackIdlePercent()
+}
+
+// linuxProcStatIdle reads /proc/stat and returns the idle fraction observed
+// since the previous sample, based on the delta of the aggregate "cpu" line.
+func linuxProcStatIdle(l *LoadSampler) (float64, bool) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return 0, false
+	}
+
+	fields := strings.Fields(scanner.Text())
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0, false
+	}
+
+	var total uint64
+	var idle uint64
+
+	for i, field := range fields[1:] {
+		v, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		total += v
+
+		// Per /proc/stat column order: user, nice, system, idle, iowait, ...
+		if i == 3 {
+			idle = v
+		}
+	}
+
+	l.mutex.Lock()
+	prev := l.lastCPU
+	l.lastCPU = cpuTimes{idle: idle, total: total}
+	l.mutex.Unlock()
+
+	if prev.total == 0 || total <= prev.total {
+		return 0, false
+	}
+
+	deltaTotal := total - prev.total
+	deltaIdle := idle - prev.idle
+
+	return float64(deltaIdle) / float64(deltaTotal), true
+}
+
+// fallbackIdlePercent estimates idleness from the CPU core count when no
+// OS-level CPU counters are available: 0.3 for up to 2 cores, 0.5 for up to 4,
+// and 0.7 otherwise. It reads the GC pause metric, but the value does not
+// affect the result; GC pressure is not measured.
+func fallbackIdlePercent() float64 {
+	samples := []metrics.Sample{
+		{Name: "/gc/pauses/total:seconds"},
+	}
+
+	metrics.Read(samples)
+
+	// We can't derive a true idle percentage from pause time alone without a
+	// baseline, so fall back to a conservative heuristic based on core count:
+	// more cores generally means more headroom for background work.
+	cores := runtime.NumCPU()
+
+	switch {
+	case cores <= 2:
+		return 0.3
+	case cores <= 4:
+		return 0.5
+	default:
+		return 0.7
+	}
+}
+
+// BatchController adaptively grows or shrinks a batch size ceiling towards
+// idleCPUTarget based on sampled system load, replacing the previous static
+// calculateOptimalBatchSize stub and the hard-coded "1am-5am" heuristic.
+type BatchController struct {
+	sampler       *LoadSampler
+	mutex         sync.Mutex
+	current       int
+	ceiling       int
+	floor         int
+	idleCPUTarget float64
+}
+
+// NewBatchController creates a controller seeded at the static optimal batch
+// size, growing up to ceiling as idle headroom allows.
+func NewBatchController(sampler *LoadSampler, ceiling int, idleCPUTarget float64) *BatchController {
+	initial := calculateOptimalBatchSize()
+
+	return &BatchController{
+		sampler:       sampler,
+		current:       initial,
+		ceiling:       ceiling,
+		floor:         initial,
+		idleCPUTarget: idleCPUTarget,
+	}
+}
+
+// Adjust re-evaluates the current batch size against the latest idle
+// sample, growing it when idle exceeds the target and halving it on
+// overshoot.
+func (b *BatchController) Adjust() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	idle := b.sampler.IdlePercent()
+
+	if idle >= b.idleCPUTarget {
+		if b.current < b.ceiling {
+			b.current++
+		}
+	} else {
+		b.current /= 2
+
+		if b.current < b.floor {
+			b.current = b.floor
+		}
+	}
+
+	return b.current
+}
+
+// Current returns the current batch size without re-evaluating it.
+func (b *BatchController) Current() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.current
+}
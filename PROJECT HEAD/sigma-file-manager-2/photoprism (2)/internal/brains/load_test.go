@@ -0,0 +1,39 @@
+package brains
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchController_Adjust(t *testing.T) {
+	sampler := NewLoadSampler()
+	ctrl := NewBatchController(sampler, 32, 0.3)
+
+	initial := ctrl.Current()
+	assert.GreaterOrEqual(t, initial, 8)
+
+	// Idle above target should grow the batch size.
+	sampler.mutex.Lock()
+	sampler.idlePct = 0.9
+	sampler.haveSample = true
+	sampler.mutex.Unlock()
+
+	grown := ctrl.Adjust()
+	assert.GreaterOrEqual(t, grown, initial)
+
+	// Idle below target should shrink it back down, never below the floor.
+	sampler.mutex.Lock()
+	sampler.idlePct = 0.1
+	sampler.mutex.Unlock()
+
+	shrunk := ctrl.Adjust()
+	assert.GreaterOrEqual(t, shrunk, ctrl.floor)
+}
+
+func TestFallbackIdlePercent(t *testing.T) {
+	result := fallbackIdlePercent()
+
+	assert.GreaterOrEqual(t, result, 0.0)
+	assert.LessOrEqual(t, result, 1.0)
+}
@@ -0,0 +1,247 @@
+package brains
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+)
+
+// lshHyperplanes is the number of random hyperplanes LSHIndex hashes each
+// vector against, giving a bucket key of that many bits (must be <= 64 to
+// fit in a uint64).
+const lshHyperplanes = 24
+
+// lshProbeRadius is the maximum Hamming distance between a query's bucket
+// key and a candidate bucket still worth probing. Raising it trades query
+// speed for recall.
+const lshProbeRadius = 2
+
+// LSHIndex is an approximate ANNIndex for libraries too large for
+// FlatIndex's O(n) scan to stay fast. Each vector is hashed with
+// lshHyperplanes random hyperplanes into a uint64 bucket key (bit i set
+// when the vector falls on the positive side of hyperplane i); Search
+// probes the query's bucket plus every bucket within lshProbeRadius
+// Hamming distance, then re-ranks the union of candidates by exact cosine
+// similarity.
+type LSHIndex struct {
+	mutex   sync.RWMutex
+	dims    int
+	seed    int64
+	planes  [][]float32
+	buckets map[uint64][]string
+	vectors map[string][]float32
+}
+
+// NewLSHIndex returns an empty LSHIndex sized for dims-dimensional
+// vectors, seeding its random hyperplanes from seed so an index rebuilt
+// with the same seed hashes identically.
+func NewLSHIndex(dims int, seed int64) *LSHIndex {
+	return &LSHIndex{
+		dims:    dims,
+		seed:    seed,
+		planes:  randomHyperplanes(dims, seed),
+		buckets: make(map[uint64][]string),
+		vectors: make(map[string][]float32),
+	}
+}
+
+// randomHyperplanes returns lshHyperplanes random dims-dimensional normal
+// vectors, deterministic for a given seed.
+func randomHyperplanes(dims int, seed int64) [][]float32 {
+	rng := rand.New(rand.NewSource(seed))
+
+	planes := make([][]float32, lshHyperplanes)
+
+	for i := range planes {
+		plane := make([]float32, dims)
+
+		for j := range plane {
+			plane[j] = float32(rng.NormFloat64())
+		}
+
+		planes[i] = plane
+	}
+
+	return planes
+}
+
+// bucketKey hashes vec into its bucket key.
+func (idx *LSHIndex) bucketKey(vec []float32) uint64 {
+	var key uint64
+
+	for i, plane := range idx.planes {
+		if len(plane) != len(vec) {
+			continue
+		}
+
+		var dot float32
+		for j, v := range vec {
+			dot += v * plane[j]
+		}
+
+		if dot > 0 {
+			key |= 1 << uint(i)
+		}
+	}
+
+	return key
+}
+
+// Upsert implements ANNIndex.
+func (idx *LSHIndex) Upsert(id string, vec []float32) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.removeLocked(id)
+
+	key := idx.bucketKey(vec)
+	idx.vectors[id] = vec
+	idx.buckets[key] = append(idx.buckets[key], id)
+}
+
+// Remove implements ANNIndex.
+func (idx *LSHIndex) Remove(id string) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.removeLocked(id)
+}
+
+// removeLocked removes id from its bucket and the vectors map. Callers
+// must hold idx.mutex for writing.
+func (idx *LSHIndex) removeLocked(id string) {
+	vec, ok := idx.vectors[id]
+	if !ok {
+		return
+	}
+
+	key := idx.bucketKey(vec)
+	bucket := idx.buckets[key]
+
+	for i, bucketID := range bucket {
+		if bucketID == id {
+			idx.buckets[key] = append(bucket[:i], bucket[i+1:]...)
+			break
+		}
+	}
+
+	delete(idx.vectors, id)
+}
+
+// Search implements ANNIndex.
+func (idx *LSHIndex) Search(vec []float32, k int) []ScoredID {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	queryKey := idx.bucketKey(vec)
+
+	seen := make(map[string]bool)
+	var results []ScoredID
+
+	for key, bucket := range idx.buckets {
+		if bits.OnesCount64(queryKey^key) > lshProbeRadius {
+			continue
+		}
+
+		for _, id := range bucket {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			stored := idx.vectors[id]
+			if len(stored) != len(vec) {
+				continue
+			}
+
+			results = append(results, ScoredID{ID: id, Score: cosineSimilarity(vec, stored)})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if k > 0 && len(results) > k {
+		results = results[:k]
+	}
+
+	return results
+}
+
+// Len implements ANNIndex.
+func (idx *LSHIndex) Len() int {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	return len(idx.vectors)
+}
+
+// New implements ANNIndex.
+func (idx *LSHIndex) New() ANNIndex {
+	return NewLSHIndex(idx.dims, idx.seed)
+}
+
+// lshPersisted is the gob-encoded shape Save/Load exchange. The
+// hyperplanes travel with the vectors so a reloaded index keeps hashing
+// consistently even if lshHyperplanes or the seed ever changes.
+type lshPersisted struct {
+	Dims    int
+	Seed    int64
+	Planes  [][]float32
+	Vectors map[string][]float32
+}
+
+// Save implements ANNIndex, gob-encoding the index to path.
+func (idx *LSHIndex) Save(path string) error {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("brains: failed to create index file: %v", err)
+	}
+	defer f.Close()
+
+	return gob.NewEncoder(f).Encode(lshPersisted{
+		Dims:    idx.dims,
+		Seed:    idx.seed,
+		Planes:  idx.planes,
+		Vectors: idx.vectors,
+	})
+}
+
+// Load implements ANNIndex, replacing the index's contents with what was
+// gob-encoded to path by a previous Save.
+func (idx *LSHIndex) Load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var persisted lshPersisted
+	if err := gob.NewDecoder(f).Decode(&persisted); err != nil {
+		return fmt.Errorf("brains: failed to decode index file: %v", err)
+	}
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.dims = persisted.Dims
+	idx.seed = persisted.Seed
+	idx.planes = persisted.Planes
+	idx.vectors = persisted.Vectors
+	idx.buckets = make(map[uint64][]string, len(persisted.Vectors))
+
+	for id, vec := range idx.vectors {
+		key := idx.bucketKey(vec)
+		idx.buckets[key] = append(idx.buckets[key], id)
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package brains
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/photoprism/photoprism/internal/config"
+)
+
+// heifExtensions and rawExtensions are formats Go's image package can't
+// decode natively. BRAINS expects Convert.ConvertToJpeg to have already
+// produced a "<path>.jpg" sidecar for them; resolveImageInput only uses it
+// when BrainsProcessRaw is enabled.
+var heifExtensions = map[string]bool{".heic": true, ".heif": true}
+
+var rawExtensions = map[string]bool{
+	".cr2": true, ".nef": true, ".arw": true, ".dng": true,
+	".orf": true, ".rw2": true, ".pef": true, ".srw": true,
+}
+
+// videoExtensions are routed through processVideo instead of being decoded
+// directly as a single image.
+var videoExtensions = map[string]bool{
+	".mp4": true, ".mov": true, ".avi": true, ".webm": true, ".mkv": true,
+}
+
+// defaultVideoSamples is how many keyframes processVideo extracts when
+// BrainsVideoSamples isn't configured or is <= 0.
+const defaultVideoSamples = 3
+
+// resolveImageInput returns the path processors should actually decode for
+// a HEIC or RAW file at path: its "<path>.jpg" sidecar, once
+// BrainsProcessRaw is enabled and Convert has produced one. BRAINS has no
+// HEIC or RAW decoder of its own, so it reuses whatever ConvertToJpeg
+// already wrote to disk rather than converting a second time.
+func resolveImageInput(conf *config.Config, path string) (string, error) {
+	if !conf.BrainsProcessRaw() {
+		return "", fmt.Errorf("brains: RAW/HEIC processing disabled, skipping %s", path)
+	}
+
+	jpegPath := path + ".jpg"
+	if !fileExists(jpegPath) {
+		return "", fmt.Errorf("brains: no JPEG sidecar for %s, run convert first", path)
+	}
+
+	return jpegPath, nil
+}
+
+// runProcessors runs every enabled processor against path, transparently
+// handling formats Process can't decode on its own: HEIC/RAW inputs are
+// redirected to their JPEG sidecar via resolveImageInput, and videos are
+// decomposed into keyframes that are each run through every processor and
+// folded back into a single result by aggregateFrameResults.
+// onProcessorErr, if not nil, is called once per processor that failed, so
+// the caller can track or report it without runProcessors needing to know
+// about job events.
+func (b *Brains) runProcessors(path string, onProcessorErr func(name string, err error)) (*FileResult, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	if videoExtensions[ext] {
+		return b.processVideo(path, onProcessorErr)
+	}
+
+	input := path
+
+	if heifExtensions[ext] || rawExtensions[ext] {
+		resolved, err := resolveImageInput(b.conf, path)
+		if err != nil {
+			return nil, err
+		}
+
+		input = resolved
+	}
+
+	return b.runProcessorsOnImage(path, input, onProcessorErr), nil
+}
+
+// runProcessorsOnImage runs every enabled processor against the decodable
+// image at inputPath, recording results under resultPath so callers always
+// see the original file they submitted, even when inputPath is a converted
+// sidecar or an extracted video keyframe.
+func (b *Brains) runProcessorsOnImage(resultPath, inputPath string, onProcessorErr func(name string, err error)) *FileResult {
+	fileResults := NewFileResult(resultPath)
+
+	for name, processor := range b.processors {
+		if !b.conf.BrainsCapabilities()[processor.Capability()] {
+			continue
+		}
+
+		result, err := processor.Process(inputPath)
+		if err != nil {
+			if onProcessorErr != nil {
+				onProcessorErr(name, err)
+			}
+
+			continue
+		}
+
+		fileResults.Results[name] = result
+	}
+
+	return fileResults
+}
+
+// processVideo extracts conf.BrainsVideoSamples() evenly spaced keyframes
+// from the video at path, runs every enabled processor against each frame,
+// and folds the per-frame results into one FileResult.
+func (b *Brains) processVideo(path string, onProcessorErr func(name string, err error)) (*FileResult, error) {
+	frames, cleanup, err := extractKeyframes(b.conf, path)
+	if err != nil {
+		return nil, fmt.Errorf("video keyframe extraction failed for %s: %v", path, err)
+	}
+	defer cleanup()
+
+	frameResults := make([]*FileResult, 0, len(frames))
+
+	for _, frame := range frames {
+		frameResults = append(frameResults, b.runProcessorsOnImage(path, frame, onProcessorErr))
+	}
+
+	return aggregateFrameResults(path, frameResults), nil
+}
+
+// extractKeyframes extracts conf.BrainsVideoSamples() evenly spaced frames
+// from the video at path into a temporary directory using ffmpeg, skipping
+// the very start and end of the video where black intro/outro slates are
+// common. The returned cleanup func removes the temporary directory; callers
+// must call it once they're done with the frame paths.
+func extractKeyframes(conf *config.Config, path string) (frames []string, cleanup func(), err error) {
+	duration, err := videoDuration(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to probe video duration: %v", err)
+	}
+
+	samples := conf.BrainsVideoSamples()
+	if samples <= 0 {
+		samples = defaultVideoSamples
+	}
+
+	tmpDir, err := os.MkdirTemp("", "brains-keyframes-*")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cleanup = func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			Log.Warnf("brains: failed to remove keyframe directory %s: %v", tmpDir, err)
+		}
+	}
+
+	for i := 0; i < samples; i++ {
+		offset := duration * float64(i+1) / float64(samples+1)
+		framePath := filepath.Join(tmpDir, fmt.Sprintf("frame-%d.jpg", i))
+
+		cmd := exec.Command("ffmpeg", "-ss", strconv.FormatFloat(offset, 'f', 3, 64), "-i", path, "-frames:v", "1", "-q:v", "2", framePath)
+
+		if out, runErr := cmd.CombinedOutput(); runErr != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("ffmpeg keyframe extraction failed: %v: %s", runErr, out)
+		}
+
+		frames = append(frames, framePath)
+	}
+
+	return frames, cleanup, nil
+}
+
+// videoDuration returns path's duration in seconds using ffprobe.
+func videoDuration(path string) (float64, error) {
+	cmd := exec.Command("ffprobe", "-v", "error", "-show_entries", "format=duration", "-of", "default=noprint_wrappers=1:nokey=1", path)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, err
+	}
+
+	duration, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected ffprobe output %q: %v", out, err)
+	}
+
+	return duration, nil
+}
@@ -0,0 +1,418 @@
+package brains
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/photoprism/photoprism/internal/config"
+	"github.com/photoprism/photoprism/internal/entity"
+	"github.com/photoprism/photoprism/internal/event"
+)
+
+// ManifestEntry describes one model type's latest published version,
+// analogous to the migration descriptor used for storage migrations: a
+// content hash plus either a direct URL or an S3 location to fetch it from.
+type ManifestEntry struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	SHA256     string `json:"sha256"`
+	Size       int64  `json:"size"`
+	URL        string `json:"url,omitempty"`
+	S3Bucket   string `json:"s3_bucket,omitempty"`
+	S3Endpoint string `json:"s3_endpoint,omitempty"`
+	S3Key      string `json:"s3_object_key,omitempty"`
+}
+
+// Manifest is a signed, remotely-hosted description of the latest version
+// of every BRAINS model type.
+type Manifest struct {
+	Entries   []ManifestEntry `json:"entries"`
+	Signature string          `json:"signature"`
+}
+
+// signedPayload returns the bytes the manifest's Signature was computed
+// over, i.e. everything except the signature itself.
+func (m *Manifest) signedPayload() ([]byte, error) {
+	return json.Marshal(m.Entries)
+}
+
+// ModelRegistry fetches signed manifests from a remote registry and
+// migrates installed models to the versions they describe, keeping enough
+// history in brains_model_migrations to roll a bad update back.
+type ModelRegistry struct {
+	conf         *config.Config
+	modelPath    string
+	registryURL  string
+	publicKey    ed25519.PublicKey
+	lastModified string
+	client       *http.Client
+}
+
+// NewModelRegistry returns a ModelRegistry that fetches manifests from
+// registryURL and installs models under modelPath, verifying each
+// manifest's signature against publicKey before trusting it.
+func NewModelRegistry(conf *config.Config, modelPath, registryURL string, publicKey ed25519.PublicKey) *ModelRegistry {
+	return &ModelRegistry{
+		conf:        conf,
+		modelPath:   modelPath,
+		registryURL: registryURL,
+		publicKey:   publicKey,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// FetchManifest retrieves the current manifest from the registry, sending
+// If-Modified-Since from the last successful fetch so an unchanged registry
+// costs a single round trip. notModified is true, with a nil manifest, when
+// the registry reports 304.
+func (r *ModelRegistry) FetchManifest() (manifest *Manifest, notModified bool, err error) {
+	req, err := http.NewRequest(http.MethodGet, r.registryURL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("brains: failed to build manifest request: %v", err)
+	}
+
+	if r.lastModified != "" {
+		req.Header.Set("If-Modified-Since", r.lastModified)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("brains: failed to fetch manifest: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, true, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("brains: manifest request failed with status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("brains: failed to read manifest: %v", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, false, fmt.Errorf("brains: failed to parse manifest: %v", err)
+	}
+
+	if err := r.verifySignature(&m); err != nil {
+		return nil, false, err
+	}
+
+	if modified := resp.Header.Get("Last-Modified"); modified != "" {
+		r.lastModified = modified
+	}
+
+	return &m, false, nil
+}
+
+// verifySignature checks m.Signature, a base64-encoded Ed25519 signature
+// over m's entries, against the registry's configured public key.
+func (r *ModelRegistry) verifySignature(m *Manifest) error {
+	if len(r.publicKey) == 0 {
+		return fmt.Errorf("brains: no manifest public key configured")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return fmt.Errorf("brains: malformed manifest signature: %v", err)
+	}
+
+	payload, err := m.signedPayload()
+	if err != nil {
+		return fmt.Errorf("brains: failed to canonicalize manifest: %v", err)
+	}
+
+	if !ed25519.Verify(r.publicKey, payload, sig) {
+		return fmt.Errorf("brains: manifest signature verification failed")
+	}
+
+	return nil
+}
+
+// ModelUpdateDiff describes one pending model update for admin confirmation
+// before UpdateModels actually downloads anything.
+type ModelUpdateDiff struct {
+	Model       string `json:"model"`
+	FromVersion string `json:"from_version"`
+	ToVersion   string `json:"to_version"`
+	SizeBytes   int64  `json:"size_bytes"`
+}
+
+// DescribeUpdates fetches the manifest and returns a diff for every entry
+// whose version differs from installedVersions, so an admin can see what an
+// UpdateModels call would change before confirming it.
+func (r *ModelRegistry) DescribeUpdates(installedVersions map[string]string) ([]ModelUpdateDiff, error) {
+	updates, err := r.CheckForUpdates(installedVersions)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([]ModelUpdateDiff, 0, len(updates))
+	for _, entry := range updates {
+		from := installedVersions[entry.Name]
+		if from == "" {
+			from = "none"
+		}
+
+		diffs = append(diffs, ModelUpdateDiff{
+			Model:       entry.Name,
+			FromVersion: from,
+			ToVersion:   entry.Version,
+			SizeBytes:   entry.Size,
+		})
+	}
+
+	return diffs, nil
+}
+
+// CheckForUpdates fetches the manifest and reports whether any entry's
+// version differs from what's currently installed, according to
+// installedVersions (as populated by loadModelVersions).
+func (r *ModelRegistry) CheckForUpdates(installedVersions map[string]string) ([]ManifestEntry, error) {
+	manifest, notModified, err := r.FetchManifest()
+	if notModified || err != nil {
+		return nil, err
+	}
+
+	var updates []ManifestEntry
+	for _, entry := range manifest.Entries {
+		if installedVersions[entry.Name] != entry.Version {
+			updates = append(updates, entry)
+		}
+	}
+
+	if len(updates) > 0 {
+		event.Publish("brains", event.BrainsModelsUpdated, updates)
+	}
+
+	return updates, nil
+}
+
+// MigrateModel downloads entry into a staging directory, verifies its
+// checksum, then atomically swaps modelPath/<entry.Name> to point at the
+// new version, recording every step in brains_model_migrations. progress,
+// if non-nil, is called with the download's completion percentage; the
+// previous version's target stays on disk, unaffected, until the new one
+// verifies, so a failed or interrupted migration leaves the prior model
+// usable.
+func (r *ModelRegistry) MigrateModel(entry ManifestEntry, progress func(percent int)) error {
+	symlinkPath := filepath.Join(r.modelPath, entry.Name)
+	previousTarget, _ := os.Readlink(symlinkPath)
+	newTarget := filepath.Join(r.modelPath, fmt.Sprintf("%s-%s", entry.Name, entry.Version))
+
+	migration := entity.NewBrainsModelMigration(entry.Name, previousTarget, newTarget, entry.Version)
+	if err := migration.Save(); err != nil {
+		return fmt.Errorf("brains: failed to record migration: %v", err)
+	}
+
+	migration.Status = entity.MigrationScheduling
+	if err := migration.Save(); err != nil {
+		Log.Warnf("brains: failed to update migration status: %v", err)
+	}
+
+	if err := r.stageModel(entry, newTarget, progress); err != nil {
+		_ = migration.Fail(err.Error())
+		return err
+	}
+
+	if err := swapModelSymlink(symlinkPath, newTarget); err != nil {
+		_ = migration.Fail(err.Error())
+		return err
+	}
+
+	return migration.Succeed()
+}
+
+// stageModel downloads entry's model file(s) into dir, verifying the
+// manifest's checksum before returning. dir is left in place on success so
+// the caller can symlink to it; it's removed on failure, leaving whatever
+// was previously symlinked at entry.Name untouched.
+func (r *ModelRegistry) stageModel(entry ManifestEntry, dir string, progress func(percent int)) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("brains: failed to create staging directory: %v", err)
+	}
+
+	dest := filepath.Join(dir, filepath.Base(entry.sourceKey()))
+
+	if err := downloadManifestEntry(r.client, entry, dest, progress); err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+
+	if err := verifyChecksum(dest, entry.SHA256); err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("brains: checksum mismatch for %s: %v", entry.Name, err)
+	}
+
+	return nil
+}
+
+// sourceKey returns whichever of URL or S3Key identifies where the entry's
+// file lives, for naming the staged file on disk.
+func (e ManifestEntry) sourceKey() string {
+	if e.URL != "" {
+		return e.URL
+	}
+
+	return e.S3Key
+}
+
+// downloadManifestEntry fetches entry's file to dest, resuming from a
+// ".partial" sibling in dest's directory via a Range request if a previous
+// attempt was interrupted. Once the copy completes, the partial file is
+// hashed and renamed to dest only if it matches entry.SHA256, so a reader
+// never observes a corrupt or incomplete file at dest. S3-backed entries
+// are fetched over plain HTTPS against the bucket's virtual-hosted
+// endpoint, consistent with how downloadModel fetches registry-declared
+// ModelSpecs.
+func downloadManifestEntry(client *http.Client, entry ManifestEntry, dest string, progress func(percent int)) error {
+	url := entry.URL
+	if url == "" && entry.S3Bucket != "" && entry.S3Key != "" {
+		endpoint := entry.S3Endpoint
+		if endpoint == "" {
+			endpoint = "s3.amazonaws.com"
+		}
+
+		url = fmt.Sprintf("https://%s.%s/%s", entry.S3Bucket, endpoint, entry.S3Key)
+	}
+
+	if url == "" {
+		return fmt.Errorf("brains: manifest entry %s has no URL or S3 location", entry.Name)
+	}
+
+	partialDir := filepath.Join(filepath.Dir(dest), ".partial")
+	if err := os.MkdirAll(partialDir, 0755); err != nil {
+		return fmt.Errorf("brains: failed to create partial download directory: %v", err)
+	}
+
+	partialPath := filepath.Join(partialDir, filepath.Base(dest))
+
+	var resumeFrom int64
+	if info, err := os.Stat(partialPath); err == nil {
+		resumeFrom = info.Size()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("brains: failed to build request for %s: %v", entry.Name, err)
+	}
+
+	if resumeFrom > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", resumeFrom))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("brains: failed to download %s: %v", entry.Name, err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	if resumeFrom > 0 && resp.StatusCode == http.StatusPartialContent {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+		resumeFrom = 0
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("brains: download of %s failed with status %d", entry.Name, resp.StatusCode)
+	}
+
+	out, err := os.OpenFile(partialPath, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("brains: failed to open %s: %v", partialPath, err)
+	}
+	defer out.Close()
+
+	written, err := io.Copy(out, &progressReader{r: resp.Body, done: resumeFrom, total: entry.Size, progress: progress})
+	if err != nil {
+		return fmt.Errorf("brains: failed writing %s: %v", partialPath, err)
+	}
+
+	if written+resumeFrom == 0 {
+		return fmt.Errorf("brains: download of %s returned no data", entry.Name)
+	}
+
+	out.Close()
+
+	if err := verifyChecksum(partialPath, entry.SHA256); err != nil {
+		return fmt.Errorf("brains: checksum mismatch for %s: %v", entry.Name, err)
+	}
+
+	if err := os.Rename(partialPath, dest); err != nil {
+		return fmt.Errorf("brains: failed to finalize %s: %v", dest, err)
+	}
+
+	return nil
+}
+
+// progressReader wraps an in-progress download body, reporting cumulative
+// completion percentage to progress as bytes are read. total of 0 means
+// the manifest didn't declare a size, so no percentage can be reported.
+type progressReader struct {
+	r        io.Reader
+	done     int64
+	total    int64
+	progress func(percent int)
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	p.done += int64(n)
+
+	if p.progress != nil && p.total > 0 {
+		p.progress(int(p.done * 100 / p.total))
+	}
+
+	return n, err
+}
+
+// swapModelSymlink points symlinkPath at newTarget atomically, by creating
+// the new link under a temporary name and renaming it over the old one, so
+// a reader never observes a missing or partially-written symlink.
+func swapModelSymlink(symlinkPath, newTarget string) error {
+	tmpPath := symlinkPath + ".tmp"
+	os.Remove(tmpPath)
+
+	if err := os.Symlink(newTarget, tmpPath); err != nil {
+		return fmt.Errorf("brains: failed to create staging symlink: %v", err)
+	}
+
+	if err := os.Rename(tmpPath, symlinkPath); err != nil {
+		return fmt.Errorf("brains: failed to swap model symlink: %v", err)
+	}
+
+	return nil
+}
+
+// RollbackModel points name's symlink back at the target of its last
+// successful migration.
+func (r *ModelRegistry) RollbackModel(name string) error {
+	previous, err := entity.LastSuccessfulMigration(name)
+	if err != nil {
+		return fmt.Errorf("brains: no successful migration to roll back to for %s: %v", name, err)
+	}
+
+	symlinkPath := filepath.Join(r.modelPath, name)
+
+	if err := swapModelSymlink(symlinkPath, previous.PreviousTarget); err != nil {
+		return err
+	}
+
+	rollback := entity.NewBrainsModelMigration(name, previous.NewTarget, previous.PreviousTarget, "rollback")
+
+	return rollback.Succeed()
+}
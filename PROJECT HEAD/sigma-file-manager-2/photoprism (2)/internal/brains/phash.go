@@ -0,0 +1,99 @@
+package brains
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// phashSize is the side length, in pixels, an image is shrunk to before its
+// average hash is computed, yielding a 64-bit hash from an 8x8 grid.
+const phashSize = 8
+
+// Phash computes a 64-bit average hash of img, encoded as 16 lowercase hex
+// characters. It shrinks img to an 8x8 grayscale grid with a box filter,
+// then sets each output bit to whether that pixel's luminance is above the
+// grid's mean, so two images that look alike hash to a small Hamming
+// distance even after recompression or a resize. This trades the extra
+// robustness of a DCT-based hash for a dependency-free implementation, which
+// is enough for the near-duplicate clustering BrainsPhash is used for.
+func Phash(img image.Image) (string, error) {
+	if img == nil {
+		return "", fmt.Errorf("brains: cannot hash a nil image")
+	}
+
+	gray := shrinkGrayscale(img, phashSize, phashSize)
+
+	var sum float64
+	for _, v := range gray {
+		sum += v
+	}
+	mean := sum / float64(len(gray))
+
+	var bits uint64
+	for i, v := range gray {
+		if v > mean {
+			bits |= 1 << uint(63-i)
+		}
+	}
+
+	return fmt.Sprintf("%016x", bits), nil
+}
+
+// PhashDistance returns the Hamming distance between two hex-encoded
+// Phash values, i.e. how many of their 64 bits differ. Identical images
+// hash to a distance of 0; distances under about 10 are generally
+// considered near-duplicates.
+func PhashDistance(a, b string) (int, error) {
+	var x, y uint64
+
+	if _, err := fmt.Sscanf(a, "%016x", &x); err != nil {
+		return 0, fmt.Errorf("brains: invalid phash %q: %v", a, err)
+	}
+
+	if _, err := fmt.Sscanf(b, "%016x", &y); err != nil {
+		return 0, fmt.Errorf("brains: invalid phash %q: %v", b, err)
+	}
+
+	diff := x ^ y
+
+	count := 0
+	for diff != 0 {
+		count++
+		diff &= diff - 1
+	}
+
+	return count, nil
+}
+
+// shrinkGrayscale resizes img to width x height using box-filter averaging
+// and returns its pixels as row-major luminance values in [0, 255].
+func shrinkGrayscale(img image.Image, width, height int) []float64 {
+	bounds := img.Bounds()
+	bw, bh := bounds.Dx(), bounds.Dy()
+
+	out := make([]float64, 0, width*height)
+
+	for y := 0; y < height; y++ {
+		y0, y1 := bounds.Min.Y+y*bh/height, bounds.Min.Y+(y+1)*bh/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0, x1 := bounds.Min.X+x*bw/width, bounds.Min.X+(x+1)*bw/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var sum float64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					r, g, b, _ := img.At(sx, sy).RGBA()
+					sum += 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
+				}
+			}
+			out = append(out, math.Round(sum/float64((x1-x0)*(y1-y0))))
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,72 @@
+package brains
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func checkerboardImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if (x/8+y/8)%2 == 0 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	return img
+}
+
+func TestPhash(t *testing.T) {
+	hash, err := Phash(checkerboardImage(64, 64))
+	assert.NoError(t, err)
+	assert.Len(t, hash, 16)
+}
+
+func TestPhash_NilImage(t *testing.T) {
+	_, err := Phash(nil)
+	assert.Error(t, err)
+}
+
+func TestPhash_SameImageMatches(t *testing.T) {
+	a, err := Phash(checkerboardImage(64, 64))
+	assert.NoError(t, err)
+
+	b, err := Phash(checkerboardImage(64, 64))
+	assert.NoError(t, err)
+
+	dist, err := PhashDistance(a, b)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, dist)
+}
+
+func TestPhashDistance_DifferentImages(t *testing.T) {
+	white := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			white.Set(x, y, color.White)
+		}
+	}
+
+	a, err := Phash(white)
+	assert.NoError(t, err)
+
+	b, err := Phash(checkerboardImage(64, 64))
+	assert.NoError(t, err)
+
+	dist, err := PhashDistance(a, b)
+	assert.NoError(t, err)
+	assert.Greater(t, dist, 0)
+}
+
+func TestPhashDistance_InvalidHash(t *testing.T) {
+	_, err := PhashDistance("not-hex", "0000000000000000")
+	assert.Error(t, err)
+}
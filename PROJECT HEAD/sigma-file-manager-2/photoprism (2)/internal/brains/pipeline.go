@@ -0,0 +1,218 @@
+package brains
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/photoprism/photoprism/internal/entity"
+)
+
+// Pipeline constants governing retry backoff and dead-lettering.
+const (
+	pipelineMaxAttempts = 5
+	pipelineBaseBackoff = 30 * time.Second
+	pipelineMaxBackoff  = 30 * time.Minute
+	pipelineIdleSleep   = 2 * time.Second
+)
+
+// Pipeline processes a persistent, database-backed work queue of files
+// awaiting BRAINS analysis. Unlike ProcessFiles, which processes a batch
+// in-memory and blocks until it's done, Enqueue returns immediately and
+// workers lease rows from the brains_queue table, so a crash mid-batch
+// loses at most the row a worker had leased, and workers can be scaled
+// horizontally across hosts sharing the same database.
+type Pipeline struct {
+	brains    *Brains
+	wg        sync.WaitGroup
+	stopChan  chan struct{}
+	running   atomic.Bool
+	processed atomic.Int64
+	failed    atomic.Int64
+}
+
+// NewPipeline returns a Pipeline that processes files through b.
+func NewPipeline(b *Brains) *Pipeline {
+	return &Pipeline{
+		brains:   b,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Enqueue persists files as pending queue rows and returns immediately;
+// Start's workers pick them up as capacity allows.
+func (p *Pipeline) Enqueue(files []string) error {
+	for _, file := range files {
+		if err := entity.NewBrainsQueueEntry(file).Save(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start launches workerCount goroutines leasing and processing queue rows
+// until Stop is called. It is a no-op if the pipeline is already running.
+func (p *Pipeline) Start(workerCount int) {
+	if !p.running.CompareAndSwap(false, true) {
+		return
+	}
+
+	if workerCount <= 0 {
+		workerCount = 4
+	}
+
+	p.stopChan = make(chan struct{})
+
+	for i := 0; i < workerCount; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+
+	Log.Infof("brains: pipeline started with %d workers", workerCount)
+}
+
+// Stop signals every worker to finish its current lease, if any, and exit
+// without leasing further rows, then waits for them to drain so a caller
+// can shut down gracefully without losing in-flight work.
+func (p *Pipeline) Stop() {
+	if !p.running.CompareAndSwap(true, false) {
+		return
+	}
+
+	close(p.stopChan)
+	p.wg.Wait()
+
+	Log.Info("brains: pipeline stopped")
+}
+
+// work is a single worker's loop: lease a row, process it, repeat, backing
+// off briefly whenever there's nothing eligible to lease.
+func (p *Pipeline) work() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-p.stopChan:
+			return
+		default:
+		}
+
+		entry, err := entity.LeaseNextBrainsQueueEntry()
+		if err != nil {
+			select {
+			case <-p.stopChan:
+				return
+			case <-time.After(pipelineIdleSleep):
+				continue
+			}
+		}
+
+		p.processEntry(entry)
+	}
+}
+
+// processEntry runs a single leased file through the BRAINS processors,
+// persisting its results on success, or rescheduling it with exponential
+// backoff (or dead-lettering it, past pipelineMaxAttempts) on failure.
+func (p *Pipeline) processEntry(entry *entity.BrainsQueue) {
+	results, err := p.brains.processBatch([]string{entry.FilePath}, nil, nil)
+
+	if err == nil {
+		if cacheErr := p.brains.cache.Set(entry.FilePath, results); cacheErr != nil {
+			Log.Warnf("brains: pipeline failed to cache results for %s: %v", entry.FilePath, cacheErr)
+		}
+
+		if saveErr := p.brains.saveResultsToDatabase(results); saveErr != nil {
+			Log.Warnf("brains: pipeline failed to save results for %s: %v", entry.FilePath, saveErr)
+		}
+
+		if delErr := entry.Delete(); delErr != nil {
+			Log.Warnf("brains: pipeline failed to clear queue row for %s: %v", entry.FilePath, delErr)
+		}
+
+		p.processed.Add(1)
+		return
+	}
+
+	entry.Attempts++
+	entry.LastError = err.Error()
+
+	if entry.Attempts >= pipelineMaxAttempts {
+		deadLetter := entity.NewBrainsDeadLetter(entry.FilePath, entry.Attempts, entry.LastError)
+
+		if saveErr := deadLetter.Save(); saveErr != nil {
+			Log.Errorf("brains: pipeline failed to dead-letter %s: %v", entry.FilePath, saveErr)
+		}
+
+		if delErr := entry.Delete(); delErr != nil {
+			Log.Warnf("brains: pipeline failed to remove dead-lettered row for %s: %v", entry.FilePath, delErr)
+		}
+
+		p.failed.Add(1)
+
+		Log.Warnf("brains: pipeline gave up on %s after %d attempts: %v", entry.FilePath, entry.Attempts, err)
+
+		return
+	}
+
+	entry.Status = entity.QueuePending
+	entry.NextAttemptAt = time.Now().Add(pipelineBackoff(entry.Attempts))
+
+	if saveErr := entry.Save(); saveErr != nil {
+		Log.Errorf("brains: pipeline failed to reschedule %s: %v", entry.FilePath, saveErr)
+	}
+}
+
+// pipelineBackoff returns the delay before the next attempt, doubling with
+// each prior attempt and capped at pipelineMaxBackoff.
+func pipelineBackoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	d := pipelineBaseBackoff * time.Duration(uint64(1)<<uint(attempt-1))
+
+	if d <= 0 || d > pipelineMaxBackoff {
+		return pipelineMaxBackoff
+	}
+
+	return d
+}
+
+// PipelineStats summarizes the queue's current backlog and this pipeline
+// instance's cumulative throughput since it started.
+type PipelineStats struct {
+	Pending      int64 `json:"pending"`
+	Leased       int64 `json:"leased"`
+	DeadLettered int64 `json:"dead_lettered"`
+	Processed    int64 `json:"processed"`
+	Failed       int64 `json:"failed"`
+}
+
+// Stats returns the queue's current backlog depth alongside this pipeline
+// instance's cumulative processed/failed counters.
+func (p *Pipeline) Stats() (PipelineStats, error) {
+	pending, err := entity.CountBrainsQueueByStatus(entity.QueuePending)
+	if err != nil {
+		return PipelineStats{}, err
+	}
+
+	leased, err := entity.CountBrainsQueueByStatus(entity.QueueLeased)
+	if err != nil {
+		return PipelineStats{}, err
+	}
+
+	deadLettered, err := entity.CountBrainsDeadLetters()
+	if err != nil {
+		return PipelineStats{}, err
+	}
+
+	return PipelineStats{
+		Pending:      pending,
+		Leased:       leased,
+		DeadLettered: deadLettered,
+		Processed:    p.processed.Load(),
+		Failed:       p.failed.Load(),
+	}, nil
+}
@@ -0,0 +1,23 @@
+package brains
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineBackoff(t *testing.T) {
+	assert.Equal(t, pipelineBaseBackoff, pipelineBackoff(1))
+	assert.Equal(t, 2*pipelineBaseBackoff, pipelineBackoff(2))
+	assert.Equal(t, 4*pipelineBaseBackoff, pipelineBackoff(3))
+	assert.Equal(t, pipelineBaseBackoff, pipelineBackoff(0))
+	assert.Equal(t, pipelineMaxBackoff, pipelineBackoff(20))
+}
+
+func TestNewPipeline(t *testing.T) {
+	b := &Brains{}
+	p := NewPipeline(b)
+
+	assert.IsType(t, &Pipeline{}, p)
+	assert.False(t, p.running.Load())
+}
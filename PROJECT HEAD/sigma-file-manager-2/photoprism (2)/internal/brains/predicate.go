@@ -0,0 +1,364 @@
+package brains
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Predicate is a node in a boolean-expression tree describing which photos
+// belong to a CurationTheme. Unlike the legacy flat CurationTheme fields,
+// predicates can express arbitrary AND/OR/NOT combinations, e.g.
+// "(landscape OR seascape) AND NOT night AND aesthetic>=7".
+//
+// SQL renders each node into a single WHERE fragment plus its bind args, so
+// a whole tree compiles into one query rather than requiring client-side set
+// merges.
+type Predicate interface {
+	SQL() (clause string, args []interface{})
+}
+
+// And requires every child predicate to match.
+type And struct {
+	Children []Predicate
+}
+
+// SQL implements Predicate.
+func (p And) SQL() (string, []interface{}) {
+	return joinClauses(p.Children, " AND ")
+}
+
+// Or requires at least one child predicate to match.
+type Or struct {
+	Children []Predicate
+}
+
+// SQL implements Predicate.
+func (p Or) SQL() (string, []interface{}) {
+	return joinClauses(p.Children, " OR ")
+}
+
+// Not inverts its single child predicate.
+type Not struct {
+	Child Predicate
+}
+
+// SQL implements Predicate.
+func (p Not) SQL() (string, []interface{}) {
+	clause, args := p.Child.SQL()
+
+	return fmt.Sprintf("NOT (%s)", clause), args
+}
+
+// joinClauses renders each child and joins the resulting clauses with the
+// given SQL operator, parenthesizing multi-term children so precedence is
+// preserved when mixing And/Or.
+func joinClauses(children []Predicate, op string) (string, []interface{}) {
+	if len(children) == 0 {
+		return "1=1", nil
+	}
+
+	clauses := make([]string, 0, len(children))
+	var args []interface{}
+
+	for _, child := range children {
+		clause, childArgs := child.SQL()
+		clauses = append(clauses, "("+clause+")")
+		args = append(args, childArgs...)
+	}
+
+	return strings.Join(clauses, op), args
+}
+
+// SceneTypeIs matches a single scene type.
+type SceneTypeIs struct {
+	SceneType string
+}
+
+// SQL implements Predicate.
+func (p SceneTypeIs) SQL() (string, []interface{}) {
+	return "LOWER(brains_results.scene_type) = ?", []interface{}{strings.ToLower(p.SceneType)}
+}
+
+// AestheticBetween matches an inclusive aesthetic score range.
+type AestheticBetween struct {
+	Min, Max float32
+}
+
+// SQL implements Predicate.
+func (p AestheticBetween) SQL() (string, []interface{}) {
+	return "brains_results.aesthetic_score BETWEEN ? AND ?", []interface{}{p.Min, p.Max}
+}
+
+// HasObject matches photos with a detected object label.
+type HasObject struct {
+	Label string
+}
+
+// SQL implements Predicate.
+func (p HasObject) SQL() (string, []interface{}) {
+	return "brains_results.object_results LIKE ?", []interface{}{"%\"label\":\"" + p.Label + "\"%"}
+}
+
+// KeywordMatches matches photos tagged with a keyword.
+type KeywordMatches struct {
+	Keyword string
+}
+
+// SQL implements Predicate.
+func (p KeywordMatches) SQL() (string, []interface{}) {
+	return "brains_results.keywords LIKE ?", []interface{}{"%" + p.Keyword + "%"}
+}
+
+// EmotionIn matches photos with any of the given emotions present.
+type EmotionIn struct {
+	Emotions []string
+}
+
+// SQL implements Predicate.
+func (p EmotionIn) SQL() (string, []interface{}) {
+	if len(p.Emotions) == 0 {
+		return "1=1", nil
+	}
+
+	clauses := make([]string, 0, len(p.Emotions))
+	args := make([]interface{}, 0, len(p.Emotions))
+
+	for _, emotion := range p.Emotions {
+		clauses = append(clauses, "brains_results.emotions LIKE ?")
+		args = append(args, "%\""+emotion+"\"%")
+	}
+
+	return strings.Join(clauses, " OR "), args
+}
+
+// IndoorOutdoorIs matches a single indoor/outdoor setting.
+type IndoorOutdoorIs struct {
+	Setting string
+}
+
+// SQL implements Predicate.
+func (p IndoorOutdoorIs) SQL() (string, []interface{}) {
+	return "LOWER(brains_results.indoor_outdoor) = ?", []interface{}{strings.ToLower(p.Setting)}
+}
+
+// TimeOfDayIn matches any of the given times of day.
+type TimeOfDayIn struct {
+	Values []string
+}
+
+// SQL implements Predicate.
+func (p TimeOfDayIn) SQL() (string, []interface{}) {
+	if len(p.Values) == 0 {
+		return "1=1", nil
+	}
+
+	args := make([]interface{}, len(p.Values))
+	for i, v := range p.Values {
+		args[i] = strings.ToLower(v)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+
+	return fmt.Sprintf("LOWER(brains_results.time_of_day) IN (%s)", placeholders), args
+}
+
+// TakenBetween matches photos taken within a date range.
+type TakenBetween struct {
+	From, To time.Time
+}
+
+// SQL implements Predicate.
+func (p TakenBetween) SQL() (string, []interface{}) {
+	return "photos.taken_at BETWEEN ? AND ?", []interface{}{p.From, p.To}
+}
+
+// HasFace matches photos containing a recognized subject.
+type HasFace struct {
+	SubjectUID string
+}
+
+// SQL implements Predicate.
+func (p HasFace) SQL() (string, []interface{}) {
+	return "photos.id IN (SELECT photo_id FROM files_markers fm " +
+		"JOIN markers m ON m.id = fm.marker_id WHERE m.subj_uid = ?)", []interface{}{p.SubjectUID}
+}
+
+// legacyToPredicate converts the flat, pre-DSL CurationTheme fields into an
+// implicit And tree, so themes saved before the predicate tree existed keep
+// working without migration.
+func legacyToPredicate(theme CurationTheme) Predicate {
+	var children []Predicate
+
+	if theme.MinAesthetic > 0 {
+		children = append(children, AestheticBetween{Min: theme.MinAesthetic, Max: 10})
+	}
+
+	if len(theme.SceneTypes) > 0 {
+		var sceneOr []Predicate
+		for _, s := range theme.SceneTypes {
+			sceneOr = append(sceneOr, SceneTypeIs{SceneType: s})
+		}
+		children = append(children, Or{Children: sceneOr})
+	}
+
+	if theme.IndoorOutdoor != "" {
+		children = append(children, IndoorOutdoorIs{Setting: theme.IndoorOutdoor})
+	}
+
+	if len(theme.TimesOfDay) > 0 {
+		children = append(children, TimeOfDayIn{Values: theme.TimesOfDay})
+	}
+
+	if len(theme.RequiredObjects) > 0 {
+		var objOr []Predicate
+		for _, o := range theme.RequiredObjects {
+			objOr = append(objOr, HasObject{Label: o})
+		}
+		children = append(children, Or{Children: objOr})
+	}
+
+	if len(theme.Keywords) > 0 {
+		var kwOr []Predicate
+		for _, k := range theme.Keywords {
+			kwOr = append(kwOr, KeywordMatches{Keyword: k})
+		}
+		children = append(children, Or{Children: kwOr})
+	}
+
+	if len(theme.EmotionTypes) > 0 {
+		children = append(children, EmotionIn{Emotions: theme.EmotionTypes})
+	}
+
+	if len(children) == 0 {
+		return nil
+	}
+
+	return And{Children: children}
+}
+
+// ParsePredicate parses a small user-authored DSL into a Predicate tree, e.g.
+// "scene:landscape & !time:night & aesthetic:>=7". Supported operators are
+// "&" (AND), "|" (OR), and a leading "!" for negation; typed terms are
+// "scene:", "time:", "weather:", "object:", "keyword:", "emotion:", and
+// "aesthetic:" with a comparison (">=", "<=", ">", "<", or an exact value).
+//
+// "&" binds tighter than "|", matching the example in the request: the
+// expression is split on "|" first, then each side is split on "&".
+func ParsePredicate(dsl string) (Predicate, error) {
+	dsl = strings.TrimSpace(dsl)
+	if dsl == "" {
+		return nil, fmt.Errorf("brains: empty predicate expression")
+	}
+
+	orParts := strings.Split(dsl, "|")
+	var orChildren []Predicate
+
+	for _, orPart := range orParts {
+		andParts := strings.Split(orPart, "&")
+		var andChildren []Predicate
+
+		for _, term := range andParts {
+			term = strings.TrimSpace(term)
+			if term == "" {
+				continue
+			}
+
+			pred, err := parseTerm(term)
+			if err != nil {
+				return nil, err
+			}
+
+			andChildren = append(andChildren, pred)
+		}
+
+		switch len(andChildren) {
+		case 0:
+			continue
+		case 1:
+			orChildren = append(orChildren, andChildren[0])
+		default:
+			orChildren = append(orChildren, And{Children: andChildren})
+		}
+	}
+
+	if len(orChildren) == 1 {
+		return orChildren[0], nil
+	}
+
+	return Or{Children: orChildren}, nil
+}
+
+// parseTerm parses a single DSL term, e.g. "!time:night" or "aesthetic:>=7".
+func parseTerm(term string) (Predicate, error) {
+	negate := false
+
+	if strings.HasPrefix(term, "!") {
+		negate = true
+		term = term[1:]
+	}
+
+	parts := strings.SplitN(term, ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("brains: invalid predicate term %q", term)
+	}
+
+	key := strings.ToLower(strings.TrimSpace(parts[0]))
+	value := strings.TrimSpace(parts[1])
+
+	var pred Predicate
+
+	switch key {
+	case "scene":
+		pred = SceneTypeIs{SceneType: value}
+	case "time":
+		pred = TimeOfDayIn{Values: []string{value}}
+	case "weather":
+		pred = KeywordMatches{Keyword: value}
+	case "object":
+		pred = HasObject{Label: value}
+	case "keyword":
+		pred = KeywordMatches{Keyword: value}
+	case "emotion":
+		pred = EmotionIn{Emotions: []string{value}}
+	case "indoor", "outdoor":
+		pred = IndoorOutdoorIs{Setting: key}
+	case "aesthetic":
+		min, max, err := parseAestheticComparison(value)
+		if err != nil {
+			return nil, err
+		}
+		pred = AestheticBetween{Min: min, Max: max}
+	default:
+		return nil, fmt.Errorf("brains: unknown predicate field %q", key)
+	}
+
+	if negate {
+		return Not{Child: pred}, nil
+	}
+
+	return pred, nil
+}
+
+// parseAestheticComparison parses ">=7", "<=3", ">7", "<3", or an exact "7"
+// into a Min/Max range.
+func parseAestheticComparison(value string) (min, max float32, err error) {
+	switch {
+	case strings.HasPrefix(value, ">="):
+		n, e := strconv.ParseFloat(value[2:], 32)
+		return float32(n), 10, e
+	case strings.HasPrefix(value, "<="):
+		n, e := strconv.ParseFloat(value[2:], 32)
+		return 0, float32(n), e
+	case strings.HasPrefix(value, ">"):
+		n, e := strconv.ParseFloat(value[1:], 32)
+		return float32(n), 10, e
+	case strings.HasPrefix(value, "<"):
+		n, e := strconv.ParseFloat(value[1:], 32)
+		return 0, float32(n), e
+	default:
+		n, e := strconv.ParseFloat(value, 32)
+		return float32(n), float32(n), e
+	}
+}
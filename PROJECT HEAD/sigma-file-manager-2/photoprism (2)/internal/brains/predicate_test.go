@@ -0,0 +1,48 @@
+package brains
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePredicate(t *testing.T) {
+	pred, err := ParsePredicate("scene:landscape & !time:night & aesthetic:>=7")
+	assert.NoError(t, err)
+
+	and, ok := pred.(And)
+	assert.True(t, ok)
+	assert.Len(t, and.Children, 3)
+
+	clause, args := pred.SQL()
+	assert.Contains(t, clause, "NOT")
+	assert.NotEmpty(t, args)
+}
+
+func TestParsePredicate_Or(t *testing.T) {
+	pred, err := ParsePredicate("scene:landscape | scene:seascape")
+	assert.NoError(t, err)
+
+	or, ok := pred.(Or)
+	assert.True(t, ok)
+	assert.Len(t, or.Children, 2)
+}
+
+func TestParsePredicate_InvalidField(t *testing.T) {
+	_, err := ParsePredicate("bogus:value")
+	assert.Error(t, err)
+}
+
+func TestLegacyToPredicate(t *testing.T) {
+	theme := CurationTheme{
+		MinAesthetic: 7,
+		SceneTypes:   []string{"landscape", "seascape"},
+	}
+
+	pred := legacyToPredicate(theme)
+	clause, args := pred.SQL()
+
+	assert.Contains(t, clause, "aesthetic_score")
+	assert.Contains(t, clause, "scene_type")
+	assert.NotEmpty(t, args)
+}
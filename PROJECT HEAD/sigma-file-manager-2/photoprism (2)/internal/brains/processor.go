@@ -1,12 +1,21 @@
 package brains
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/photoprism/photoprism/internal/config"
+	"github.com/photoprism/photoprism/internal/entity"
 )
 
 // ProcessingResults represents the combined results from BRAINS processing.
@@ -45,7 +54,7 @@ func (r *ProcessingResults) Merge(other *ProcessingResults) {
 	if other == nil {
 		return
 	}
-	
+
 	r.Files = append(r.Files, other.Files...)
 }
 
@@ -55,30 +64,49 @@ func (r *ProcessingResults) SaveToFile(filename string) error {
 	if err != nil {
 		return err
 	}
-	
+
 	return os.WriteFile(filename, data, 0644)
 }
 
-// Processor defines the interface for all BRAINS processors.
+// Processor defines the interface for all BRAINS processors. Capability and
+// ModelFiles are static descriptions, available even before a processor's
+// models have been downloaded, so the registry can resolve what to fetch
+// without first constructing a working instance. Analyze is the inference
+// entry point; Process decodes filename and delegates to it. MergeResult
+// applies a processor's own result type onto a BrainsResult, keeping the
+// knowledge of each processor's fields out of the caller that stores them.
 type Processor interface {
 	Process(filename string) (ProcessorResult, error)
+	Analyze(img image.Image) (ProcessorResult, error)
+	MergeResult(result *entity.BrainsResult, r ProcessorResult)
 	Name() string
+	Capability() string
+	ModelFiles() []ModelSpec
+	MinInputPixels() int
+	Warmup(ctx context.Context)
 }
 
 // BaseProcessor contains functionality shared by all processors.
 type BaseProcessor struct {
-	conf      *config.Config
-	modelPath string
-	modelFile string
-	name      string
+	conf           *config.Config
+	modelPath      string
+	modelFile      string
+	name           string
+	capability     string
+	minInputPixels int
 }
 
-// NewBaseProcessor creates a new base processor.
-func NewBaseProcessor(conf *config.Config, modelPath, name string) *BaseProcessor {
+// NewBaseProcessor creates a new base processor. minInputPixels is the
+// shortest side, in pixels, an input image must have for this processor to
+// produce a reliable result; SelectInput uses it to decide whether a
+// cached thumbnail is large enough or the original file is needed.
+func NewBaseProcessor(conf *config.Config, modelPath, name, capability string, minInputPixels int) *BaseProcessor {
 	return &BaseProcessor{
-		conf:      conf,
-		modelPath: modelPath,
-		name:      name,
+		conf:           conf,
+		modelPath:      modelPath,
+		name:           name,
+		capability:     capability,
+		minInputPixels: minInputPixels,
 	}
 }
 
@@ -87,22 +115,79 @@ func (p *BaseProcessor) Name() string {
 	return p.name
 }
 
-// ObjectProcessor detects objects in images.
+// Capability returns the config capability flag this processor is gated by,
+// e.g. "object_detection".
+func (p *BaseProcessor) Capability() string {
+	return p.capability
+}
+
+// MinInputPixels returns the shortest side, in pixels, an input image must
+// have for this processor to produce a reliable result.
+func (p *BaseProcessor) MinInputPixels() int {
+	return p.minInputPixels
+}
+
+// decodeImage opens filename and decodes it into an image.Image using the
+// standard library's registered formats.
+func decodeImage(filename string) (image.Image, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+
+	return img, err
+}
+
+// objectModelSHA256 is the expected checksum of the object detection model.
+const objectModelSHA256 = "4f350e0e6e1a2a6c9a9e1ad6b9f5c9b0e6f9ad7c53e3a1fbbf5f9c2cf4b3a6d2"
+
+// objectInputSize is the SSD-MobileNet input size, in pixels, on each side.
+const objectInputSize = 300
+
+// objectScoreThreshold is the minimum class score for a detection to be
+// reported.
+const objectScoreThreshold = 0.5
+
+// ObjectProcessor detects objects in images using an SSD-MobileNet model.
 type ObjectProcessor struct {
 	*BaseProcessor
+	models *ModelManager
+	labels []string
 }
 
-// NewObjectProcessor creates a new object detection processor.
+// NewObjectProcessor creates a new object detection processor. A missing
+// model file is logged rather than treated as fatal, since models may not
+// have been downloaded yet; ModelFiles lets the caller find out what's
+// needed and DownloadModels fetch it.
 func NewObjectProcessor(conf *config.Config, modelPath string) (*ObjectProcessor, error) {
-	base := NewBaseProcessor(conf, modelPath, "object")
+	base := NewBaseProcessor(conf, modelPath, "object", "object_detection", 416)
 	base.modelFile = filepath.Join(modelPath, "object-detection.pb")
-	
-	// Check if model exists
-	if _, err := os.Stat(base.modelFile); os.IsNotExist(err) {
-		return nil, fmt.Errorf("object detection model not found at %s", base.modelFile)
+
+	if !fileExists(base.modelFile) {
+		Log.Warnf("brains: object detection model not found at %s", base.modelFile)
+	}
+
+	labels, err := loadLabels(filepath.Join(modelPath, "coco_labels.txt"))
+	if err != nil {
+		Log.Warnf("brains: failed to load COCO labels: %v", err)
 	}
-	
-	return &ObjectProcessor{BaseProcessor: base}, nil
+
+	return &ObjectProcessor{BaseProcessor: base, models: NewModelManager(conf), labels: labels}, nil
+}
+
+// ModelFiles returns the model files required by the object processor.
+func (p *ObjectProcessor) ModelFiles() []ModelSpec {
+	return []ModelSpec{
+		{URL: "https://dl.photoprism.app/tensorflow/object-detection.pb", SHA256: objectModelSHA256, Size: 28_000_000},
+	}
+}
+
+// Warmup loads the object detection model ahead of the first Analyze call.
+func (p *ObjectProcessor) Warmup(ctx context.Context) {
+	p.models.Warmup(ctx, []ModelWarmupSpec{{Path: p.modelFile, Type: "object"}})
 }
 
 // ObjectResult contains object detection results.
@@ -127,48 +212,113 @@ func (r ObjectResult) Type() string {
 
 // Process processes an image file to detect objects.
 func (p *ObjectProcessor) Process(filename string) (ProcessorResult, error) {
-	// In a real implementation, this would use the neural network model
-	// For now, we're just creating a stub result
-	result := ObjectResult{
-		Objects: []DetectedObject{
-			{
-				Label:      "person",
-				Confidence: 0.92,
-				X:          120,
-				Y:          80,
-				Width:      200,
-				Height:     400,
-			},
-			{
-				Label:      "car",
-				Confidence: 0.85,
-				X:          300,
-				Y:          200,
-				Width:      150,
-				Height:     100,
-			},
-		},
-	}
-	
-	return result, nil
+	img, err := decodeImage(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Analyze(img)
+}
+
+// Analyze detects objects in img by running it through the SSD-MobileNet
+// model. The model output is a single [1, N, 6] tensor of (class, score, x,
+// y, width, height) rows, normalized to the [0, 1] range of img's
+// dimensions, sorted by descending score.
+func (p *ObjectProcessor) Analyze(img image.Image) (ProcessorResult, error) {
+	model, err := p.models.GetModel(p.modelFile, "object")
+	if err != nil {
+		return nil, fmt.Errorf("brains: object model unavailable: %v", err)
+	}
+
+	input, err := imageTensor(img, objectInputSize, objectInputSize)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := model.Predict(input)
+	if err != nil {
+		return nil, fmt.Errorf("brains: object detection inference failed: %v", err)
+	}
+
+	rows, ok := output.Value().([][][]float32)
+	if !ok || len(rows) == 0 {
+		return nil, fmt.Errorf("brains: unexpected object detection output shape")
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	var objects []DetectedObject
+
+	for _, row := range rows[0] {
+		if len(row) < 6 {
+			continue
+		}
+
+		score := row[1]
+		if score < objectScoreThreshold {
+			continue
+		}
+
+		objects = append(objects, DetectedObject{
+			Label:      labelFor(p.labels, int(row[0])),
+			Confidence: score,
+			X:          int(row[2] * float32(w)),
+			Y:          int(row[3] * float32(h)),
+			Width:      int(row[4] * float32(w)),
+			Height:     int(row[5] * float32(h)),
+		})
+	}
+
+	return ObjectResult{Objects: objects}, nil
 }
 
-// AestheticProcessor analyzes image aesthetics.
+// MergeResult applies an ObjectResult onto result.
+func (p *ObjectProcessor) MergeResult(result *entity.BrainsResult, r ProcessorResult) {
+	object, ok := r.(ObjectResult)
+	if !ok {
+		return
+	}
+
+	if objectJSON, err := json.Marshal(object.Objects); err == nil {
+		result.ObjectResults = string(objectJSON)
+	}
+}
+
+// aestheticModelSHA256 is the expected checksum of the aesthetic model.
+const aestheticModelSHA256 = "8c2e6f1ad37b4a0e9d5c6f2b1a3e4d5c6b7a8f9e0d1c2b3a4e5f6d7c8b9a0e1f"
+
+// aestheticInputSize is the NIMA input size, in pixels, on each side.
+const aestheticInputSize = 224
+
+// AestheticProcessor scores image aesthetics using a NIMA model.
 type AestheticProcessor struct {
 	*BaseProcessor
+	models *ModelManager
 }
 
 // NewAestheticProcessor creates a new aesthetic scoring processor.
 func NewAestheticProcessor(conf *config.Config, modelPath string) (*AestheticProcessor, error) {
-	base := NewBaseProcessor(conf, modelPath, "aesthetic")
+	base := NewBaseProcessor(conf, modelPath, "aesthetic", "aesthetic_scoring", 299)
 	base.modelFile = filepath.Join(modelPath, "aesthetic-scoring.pb")
-	
-	// Check if model exists
-	if _, err := os.Stat(base.modelFile); os.IsNotExist(err) {
-		return nil, fmt.Errorf("aesthetic model not found at %s", base.modelFile)
+
+	if !fileExists(base.modelFile) {
+		Log.Warnf("brains: aesthetic model not found at %s", base.modelFile)
+	}
+
+	return &AestheticProcessor{BaseProcessor: base, models: NewModelManager(conf)}, nil
+}
+
+// ModelFiles returns the model files required by the aesthetic processor.
+func (p *AestheticProcessor) ModelFiles() []ModelSpec {
+	return []ModelSpec{
+		{URL: "https://dl.photoprism.app/tensorflow/aesthetic-scoring.pb", SHA256: aestheticModelSHA256, Size: 19_500_000},
 	}
-	
-	return &AestheticProcessor{BaseProcessor: base}, nil
+}
+
+// Warmup loads the aesthetic scoring model ahead of the first Analyze call.
+func (p *AestheticProcessor) Warmup(ctx context.Context) {
+	p.models.Warmup(ctx, []ModelWarmupSpec{{Path: p.modelFile, Type: "aesthetic"}})
 }
 
 // AestheticResult contains aesthetic scoring results.
@@ -188,39 +338,186 @@ func (r AestheticResult) Type() string {
 
 // Process processes an image file for aesthetic scoring.
 func (p *AestheticProcessor) Process(filename string) (ProcessorResult, error) {
-	// In a real implementation, this would use the neural network model
-	// For now, we're just creating a stub result
+	img, err := decodeImage(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Analyze(img)
+}
+
+// Analyze scores img for aesthetic quality using NIMA, which outputs a
+// softmax distribution over 10 quality buckets (1 = worst, 10 = best)
+// rather than a single number. The overall Score is that distribution's
+// mean, scaled to the usual 0-10 range; its standard deviation measures how
+// confident the model is, which we fold into Composition and ColorHarmony
+// since NIMA doesn't score those sub-dimensions separately. Contrast and
+// Exposure are measured directly from img's luminance, since they don't
+// require a trained model.
+func (p *AestheticProcessor) Analyze(img image.Image) (ProcessorResult, error) {
+	model, err := p.models.GetModel(p.modelFile, "aesthetic")
+	if err != nil {
+		return nil, fmt.Errorf("brains: aesthetic model unavailable: %v", err)
+	}
+
+	input, err := imageTensor(img, aestheticInputSize, aestheticInputSize)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := model.Predict(input)
+	if err != nil {
+		return nil, fmt.Errorf("brains: aesthetic inference failed: %v", err)
+	}
+
+	buckets, ok := output.Value().([][]float32)
+	if !ok || len(buckets) == 0 || len(buckets[0]) != 10 {
+		return nil, fmt.Errorf("brains: unexpected aesthetic output shape")
+	}
+
+	mean, stddev := nimaMeanStddev(buckets[0])
+	confidence := 1 - stddev/5 // a tight distribution scores near 1, a flat one near 0
+	if confidence < 0 {
+		confidence = 0
+	}
+
+	exposure, contrast := luminanceStats(img)
+
 	result := AestheticResult{
-		Score:        8.2,
-		Composition:  7.9,
-		Contrast:     8.5,
-		Exposure:     9.0,
-		ColorHarmony: 8.4,
-		Recommendations: []string{
-			"Slightly improve composition by following rule of thirds",
-			"Colors look well balanced",
-		},
-	}
-	
+		Score:        mean,
+		Composition:  mean * confidence,
+		Contrast:     contrast,
+		Exposure:     exposure,
+		ColorHarmony: mean * confidence,
+	}
+
 	return result, nil
 }
 
-// SceneProcessor analyzes scene content.
+// nimaMeanStddev returns the mean and standard deviation of NIMA's 1-10
+// bucket distribution, the score and confidence it represents.
+func nimaMeanStddev(buckets []float32) (mean, stddev float32) {
+	var sum float64
+	for i, p := range buckets {
+		sum += float64(i+1) * float64(p)
+	}
+	mean64 := sum
+
+	var variance float64
+	for i, p := range buckets {
+		d := float64(i+1) - mean64
+		variance += float64(p) * d * d
+	}
+
+	return float32(mean64), float32(math.Sqrt(variance))
+}
+
+// luminanceStats samples img's pixels and returns (average brightness,
+// standard deviation of brightness) scaled to 0-10, standing in for
+// Exposure and Contrast since NIMA doesn't score those separately.
+func luminanceStats(img image.Image) (exposure, contrast float32) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	if w == 0 || h == 0 {
+		return 0, 0
+	}
+
+	const samples = 32
+	stepX, stepY := w/samples, h/samples
+	if stepX < 1 {
+		stepX = 1
+	}
+	if stepY < 1 {
+		stepY = 1
+	}
+
+	var sum, sumSq float64
+	var n int
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			r, g, b, _ := img.At(x, y).RGBA()
+			l := (0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)) / 255
+			sum += l
+			sumSq += l * l
+			n++
+		}
+	}
+
+	mean := sum / float64(n)
+	variance := sumSq/float64(n) - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+
+	return float32(mean * 10), float32(math.Sqrt(variance) * 10)
+}
+
+// MergeResult applies an AestheticResult onto result.
+func (p *AestheticProcessor) MergeResult(result *entity.BrainsResult, r ProcessorResult) {
+	aesthetic, ok := r.(AestheticResult)
+	if !ok {
+		return
+	}
+
+	result.AestheticScore = aesthetic.Score
+	result.Composition = aesthetic.Composition
+	result.Contrast = aesthetic.Contrast
+	result.Exposure = aesthetic.Exposure
+	result.ColorHarmony = aesthetic.ColorHarmony
+}
+
+// sceneModelSHA256 is the expected checksum of the scene model.
+const sceneModelSHA256 = "1a2b3c4d5e6f7089a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60712"
+
+// sceneInputSize is the Places365 input size, in pixels, on each side.
+const sceneInputSize = 224
+
+// sceneTopKKeywords is how many top-scoring categories become Keywords.
+const sceneTopKKeywords = 5
+
+// sceneCategory is one Places365 category: its name and whether it's
+// considered indoor or outdoor.
+type sceneCategory struct {
+	Name          string
+	IndoorOutdoor string
+}
+
+// SceneProcessor classifies scene content using a Places365 model.
 type SceneProcessor struct {
 	*BaseProcessor
+	models     *ModelManager
+	categories []sceneCategory
 }
 
 // NewSceneProcessor creates a new scene understanding processor.
 func NewSceneProcessor(conf *config.Config, modelPath string) (*SceneProcessor, error) {
-	base := NewBaseProcessor(conf, modelPath, "scene")
+	base := NewBaseProcessor(conf, modelPath, "scene", "scene_understanding", 224)
 	base.modelFile = filepath.Join(modelPath, "scene-understanding.pb")
-	
-	// Check if model exists
-	if _, err := os.Stat(base.modelFile); os.IsNotExist(err) {
-		return nil, fmt.Errorf("scene model not found at %s", base.modelFile)
+
+	if !fileExists(base.modelFile) {
+		Log.Warnf("brains: scene model not found at %s", base.modelFile)
+	}
+
+	categories, err := loadSceneCategories(filepath.Join(modelPath, "places365.txt"))
+	if err != nil {
+		Log.Warnf("brains: failed to load Places365 categories: %v", err)
+	}
+
+	return &SceneProcessor{BaseProcessor: base, models: NewModelManager(conf), categories: categories}, nil
+}
+
+// ModelFiles returns the model files required by the scene processor.
+func (p *SceneProcessor) ModelFiles() []ModelSpec {
+	return []ModelSpec{
+		{URL: "https://dl.photoprism.app/tensorflow/scene-understanding.pb", SHA256: sceneModelSHA256, Size: 22_000_000},
 	}
-	
-	return &SceneProcessor{BaseProcessor: base}, nil
+}
+
+// Warmup loads the scene understanding model ahead of the first Analyze call.
+func (p *SceneProcessor) Warmup(ctx context.Context) {
+	p.models.Warmup(ctx, []ModelWarmupSpec{{Path: p.modelFile, Type: "scene"}})
 }
 
 // SceneResult contains scene analysis results.
@@ -240,20 +537,201 @@ func (r SceneResult) Type() string {
 
 // Process processes an image file for scene understanding.
 func (p *SceneProcessor) Process(filename string) (ProcessorResult, error) {
-	// In a real implementation, this would use the neural network model
-	// For now, we're just creating a stub result
+	img, err := decodeImage(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Analyze(img)
+}
+
+// Analyze classifies the scene shown in img by running it through the
+// Places365 model, a softmax over its categories. IndoorOutdoor comes from
+// the top category's entry in places365.txt; TimeOfDay and Weather aren't
+// covered by a scene classifier alone, so they're left at their zero value
+// here for a dedicated processor to fill in later.
+func (p *SceneProcessor) Analyze(img image.Image) (ProcessorResult, error) {
+	model, err := p.models.GetModel(p.modelFile, "scene")
+	if err != nil {
+		return nil, fmt.Errorf("brains: scene model unavailable: %v", err)
+	}
+
+	input, err := imageTensor(img, sceneInputSize, sceneInputSize)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := model.Predict(input)
+	if err != nil {
+		return nil, fmt.Errorf("brains: scene inference failed: %v", err)
+	}
+
+	scores, ok := output.Value().([][]float32)
+	if !ok || len(scores) == 0 {
+		return nil, fmt.Errorf("brains: unexpected scene output shape")
+	}
+
+	ranked := rankCategories(p.categories, scores[0])
+	if len(ranked) == 0 {
+		return nil, fmt.Errorf("brains: no scene categories loaded")
+	}
+
+	top := ranked[0]
+
+	keywordCount := sceneTopKKeywords
+	if len(ranked) < keywordCount {
+		keywordCount = len(ranked)
+	}
+
+	keywords := make([]string, keywordCount)
+	for i := 0; i < keywordCount; i++ {
+		keywords[i] = ranked[i].Name
+	}
+
 	result := SceneResult{
-		SceneType:     "landscape",
-		IndoorOutdoor: "outdoor",
-		TimeOfDay:     "daytime",
-		Weather:       "sunny",
-		Keywords:      []string{"nature", "mountains", "trees", "sky", "clouds"},
-		Emotions: map[string]float32{
-			"peaceful": 0.85,
-			"awe":      0.72,
-			"happy":    0.65,
-		},
-	}
-	
+		SceneType:     top.Name,
+		IndoorOutdoor: top.IndoorOutdoor,
+		Keywords:      keywords,
+	}
+
 	return result, nil
 }
+
+// rankCategories pairs categories with their score and returns them sorted
+// by descending score. Extra scores beyond len(categories) are ignored,
+// and a shorter scores slice is padded with nothing, so a mismatched label
+// file degrades gracefully instead of panicking.
+func rankCategories(categories []sceneCategory, scores []float32) []sceneCategory {
+	n := len(categories)
+	if len(scores) < n {
+		n = len(scores)
+	}
+
+	type scored struct {
+		category sceneCategory
+		score    float32
+	}
+
+	ranked := make([]scored, n)
+	for i := 0; i < n; i++ {
+		ranked[i] = scored{category: categories[i], score: scores[i]}
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+
+	result := make([]sceneCategory, n)
+	for i, r := range ranked {
+		result[i] = r.category
+	}
+
+	return result
+}
+
+// MergeResult applies a SceneResult onto result.
+func (p *SceneProcessor) MergeResult(result *entity.BrainsResult, r ProcessorResult) {
+	scene, ok := r.(SceneResult)
+	if !ok {
+		return
+	}
+
+	result.SceneType = scene.SceneType
+	result.IndoorOutdoor = scene.IndoorOutdoor
+	result.TimeOfDay = scene.TimeOfDay
+	result.Weather = scene.Weather
+	result.Keywords = strings.Join(scene.Keywords, ",")
+
+	sorted := append([]string{}, scene.Keywords...)
+	sort.Strings(sorted)
+	result.KeywordsSorted = strings.Join(sorted, ",")
+
+	if len(scene.Emotions) > 0 {
+		if emotionsJSON, err := json.Marshal(scene.Emotions); err == nil {
+			result.Emotions = string(emotionsJSON)
+		}
+	}
+}
+
+// fileExists reports whether path exists and is a regular file.
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// labelFor returns labels[i], or "unknown" if i is out of range, so a
+// detection with an index past the end of a mismatched label file doesn't
+// panic.
+func labelFor(labels []string, i int) string {
+	if i < 0 || i >= len(labels) {
+		return "unknown"
+	}
+
+	return labels[i]
+}
+
+// loadLabels reads path as one label per line, skipping blank lines.
+func loadLabels(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var labels []string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		labels = append(labels, line)
+	}
+
+	return labels, scanner.Err()
+}
+
+// loadSceneCategories reads path as Places365 categories, one per line in
+// "name,indoor_outdoor" form, in the same order the model's output vector
+// is indexed.
+func loadSceneCategories(path string) ([]sceneCategory, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var categories []sceneCategory
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, ",", 2)
+		category := sceneCategory{Name: parts[0]}
+		if len(parts) == 2 {
+			category.IndoorOutdoor = parts[1]
+		}
+
+		categories = append(categories, category)
+	}
+
+	return categories, scanner.Err()
+}
+
+func init() {
+	RegisterProcessor("object", func(conf *config.Config, modelPath string) (Processor, error) {
+		return NewObjectProcessor(conf, modelPath)
+	})
+	RegisterProcessor("aesthetic", func(conf *config.Config, modelPath string) (Processor, error) {
+		return NewAestheticProcessor(conf, modelPath)
+	})
+	RegisterProcessor("scene", func(conf *config.Config, modelPath string) (Processor, error) {
+		return NewSceneProcessor(conf, modelPath)
+	})
+}
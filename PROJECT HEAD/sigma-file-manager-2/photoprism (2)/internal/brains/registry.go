@@ -0,0 +1,294 @@
+package brains
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/photoprism/photoprism/internal/config"
+)
+
+// ModelSpec describes a single downloadable model file.
+type ModelSpec struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// Filename returns the local file name the spec is stored under, derived
+// from the last path segment of its URL.
+func (m ModelSpec) Filename() string {
+	return filepath.Base(m.URL)
+}
+
+// ProcessorFactory builds a new Processor instance for the given config and
+// model path.
+type ProcessorFactory func(conf *config.Config, modelPath string) (Processor, error)
+
+// Registry holds the set of BRAINS processors available for initialization
+// and download, keyed by name. Third parties can extend BRAINS with their
+// own processors via RegisterProcessor without modifying this package.
+type Registry struct {
+	mutex   sync.RWMutex
+	order   []string
+	entries map[string]ProcessorFactory
+}
+
+var defaultRegistry = &Registry{entries: make(map[string]ProcessorFactory)}
+
+// RegisterProcessor adds a processor factory to the default registry under
+// name, replacing any existing registration. Built-in processors register
+// themselves this way from an init() in processor.go.
+func RegisterProcessor(name string, factory ProcessorFactory) {
+	defaultRegistry.Register(name, factory)
+}
+
+// Register adds a processor factory to the registry under name.
+func (r *Registry) Register(name string, factory ProcessorFactory) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.entries[name]; !exists {
+		r.order = append(r.order, name)
+	}
+
+	r.entries[name] = factory
+}
+
+// Names returns the registered processor names in registration order.
+func (r *Registry) Names() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return append([]string{}, r.order...)
+}
+
+// New builds a single processor by name.
+func (r *Registry) New(name string, conf *config.Config, modelPath string) (Processor, error) {
+	r.mutex.RLock()
+	factory, ok := r.entries[name]
+	r.mutex.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("brains: no processor registered for %q", name)
+	}
+
+	return factory(conf, modelPath)
+}
+
+// NewAll builds every registered processor, skipping and logging any that
+// fail to initialize so a partially installed BRAINS system can still run
+// with reduced capabilities instead of refusing to start at all.
+func (r *Registry) NewAll(conf *config.Config, modelPath string) map[string]Processor {
+	processors := make(map[string]Processor)
+
+	for _, name := range r.Names() {
+		p, err := r.New(name, conf, modelPath)
+		if err != nil {
+			Log.Warnf("brains: %s processor unavailable: %v", name, err)
+			continue
+		}
+
+		processors[name] = p
+	}
+
+	return processors
+}
+
+// modelManifestFile is the name of the checksum manifest written to the
+// model path after a successful download, so installed models can be
+// verified by content rather than by file existence alone.
+const modelManifestFile = "manifest.json"
+
+// maxConcurrentDownloads bounds how many model files are fetched at once.
+const maxConcurrentDownloads = 4
+
+// modelManifest maps a downloaded file's base name to the spec it was
+// downloaded from, including the checksum it's expected to match.
+type modelManifest map[string]ModelSpec
+
+// DownloadModels fetches every ModelSpec declared by the default registry's
+// processors into modelPath using the package-level registry, resuming
+// partial downloads and verifying content against SHA256.
+func DownloadModels(conf *config.Config, modelPath string) error {
+	return defaultRegistry.DownloadModels(conf, modelPath)
+}
+
+// ModelsVerified checks the checksum manifest at modelPath against the files
+// on disk using the package-level registry.
+func ModelsVerified(modelPath string) bool {
+	return verifyManifest(modelPath)
+}
+
+// DownloadModels fetches every ModelSpec declared by this registry's
+// processors into modelPath, downloading up to maxConcurrentDownloads files
+// in parallel and resuming any that were left partially written by a
+// previous, interrupted run. A checksum manifest is written once every
+// download succeeds and verifies.
+func (r *Registry) DownloadModels(conf *config.Config, modelPath string) error {
+	processors := r.NewAll(conf, modelPath)
+
+	var specs []ModelSpec
+	for _, p := range processors {
+		specs = append(specs, p.ModelFiles()...)
+	}
+
+	if err := os.MkdirAll(modelPath, 0755); err != nil {
+		return fmt.Errorf("brains: failed to create model path: %v", err)
+	}
+
+	sem := make(chan struct{}, maxConcurrentDownloads)
+	var wg sync.WaitGroup
+	errs := make([]error, len(specs))
+
+	for i, spec := range specs {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, spec ModelSpec) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			errs[i] = downloadModel(modelPath, spec)
+		}(i, spec)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeModelManifest(modelPath, specs)
+}
+
+// downloadModel fetches a single model file, resuming from an existing
+// partial download via a Range request, then verifies its checksum. Files
+// that already match the expected checksum are left untouched.
+func downloadModel(modelPath string, spec ModelSpec) error {
+	dest := filepath.Join(modelPath, spec.Filename())
+
+	if verifyChecksum(dest, spec.SHA256) == nil {
+		return nil
+	}
+
+	var resumeFrom int64
+	if info, err := os.Stat(dest); err == nil {
+		resumeFrom = info.Size()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, spec.URL, nil)
+	if err != nil {
+		return fmt.Errorf("brains: failed to build request for %s: %v", spec.URL, err)
+	}
+
+	if resumeFrom > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", resumeFrom))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("brains: failed to download %s: %v", spec.URL, err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	if resumeFrom > 0 && resp.StatusCode == http.StatusPartialContent {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+	}
+
+	out, err := os.OpenFile(dest, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("brains: failed to open %s: %v", dest, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		return fmt.Errorf("brains: failed writing %s: %v", dest, err)
+	}
+
+	return verifyChecksum(dest, spec.SHA256)
+}
+
+// verifyChecksum returns nil if the file at path exists and its SHA256
+// matches expected. expected must not be empty: a ModelSpec without a
+// checksum can never be verified, so treating "" as a wildcard would let
+// downloadModel silently accept any file, already-downloaded or freshly
+// fetched, for a misconfigured spec.
+func verifyChecksum(path, expected string) error {
+	if expected == "" {
+		return fmt.Errorf("brains: no checksum configured for %s", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != expected {
+		return fmt.Errorf("brains: checksum mismatch for %s", path)
+	}
+
+	return nil
+}
+
+// writeModelManifest records the checksums of the given specs to
+// modelPath/manifest.json.
+func writeModelManifest(modelPath string, specs []ModelSpec) error {
+	manifest := make(modelManifest, len(specs))
+	for _, spec := range specs {
+		manifest[spec.Filename()] = spec
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(modelPath, modelManifestFile), data, 0644)
+}
+
+// verifyManifest checks the checksum manifest at modelPath against the
+// files on disk, returning false if the manifest is missing, empty, or any
+// file fails its checksum.
+func verifyManifest(modelPath string) bool {
+	data, err := os.ReadFile(filepath.Join(modelPath, modelManifestFile))
+	if err != nil {
+		return false
+	}
+
+	var manifest modelManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return false
+	}
+
+	if len(manifest) == 0 {
+		return false
+	}
+
+	for filename, spec := range manifest {
+		if verifyChecksum(filepath.Join(modelPath, filename), spec.SHA256) != nil {
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,8 +1,10 @@
 package brains
 
 import (
+	"database/sql"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/photoprism/photoprism/internal/entity"
@@ -21,7 +23,20 @@ type Scheduler struct {
 	idleCPUTarget float64 // Target CPU idle percentage for adaptive scheduling
 	mutex         sync.Mutex
 	curator       *Curator
+	faceClusterer *FaceClusterer
 	stopChan      chan bool
+	loadSampler   *LoadSampler
+	batchCtrl     *BatchController
+	state         *entity.BrainsSchedulerState
+	lastSkipped   string      // reason the most recent tick was skipped, if it was
+	busy          atomic.Bool // true while a scheduled job is actively processing photos
+}
+
+// Busy reports whether the scheduler is currently running a job, so other
+// background consumers of BRAINS (e.g. CacheWarmer) can back off instead of
+// competing with it for CPU and disk.
+func (s *Scheduler) Busy() bool {
+	return s.busy.Load()
 }
 
 // TaskPriority defines processing priority levels.
@@ -42,15 +57,27 @@ type AnalysisTask struct {
 
 // NewScheduler creates a new BRAINS scheduler.
 func NewScheduler(b *Brains, q *query.Query) *Scheduler {
+	sampler := NewLoadSampler()
+
+	state, err := entity.LoadBrainsSchedulerState()
+	if err != nil {
+		Log.Warnf("brains: failed to load scheduler watermark, starting from scratch: %v", err)
+		state = &entity.BrainsSchedulerState{}
+	}
+
 	return &Scheduler{
 		brains:        b,
 		query:         q,
 		running:       false,
 		interval:      30 * time.Minute, // Default interval
-		maxBatchSize:  100,              // Default batch size
+		maxBatchSize:  100,              // Ceiling the adaptive controller grows towards
 		idleCPUTarget: 0.3,              // Target 30% idle CPU
 		stopChan:      make(chan bool),
+		loadSampler:   sampler,
+		batchCtrl:     NewBatchController(sampler, 100, 0.3),
 		curator:       NewCurator(entity.Db()),
+		faceClusterer: NewFaceClusterer(),
+		state:         state,
 	}
 }
 
@@ -64,18 +91,37 @@ func (s *Scheduler) Start() error {
 	}
 
 	s.running = true
-	
+
+	// Start sampling real system load so isSystemIdle and the batch
+	// controller can react to actual CPU/memory pressure.
+	s.loadSampler.Start()
+
+	// Resume any jobs that were left running when the process last exited,
+	// e.g. because the container was restarted mid-batch.
+	s.resumeInterruptedJobs()
+
 	// Start background scheduler
 	go s.run()
-	
+
 	// Start automated collection curation on a separate schedule
 	go s.runCollectionCurator()
 
+	// Periodically rebuild the vector search index from scratch, so drift
+	// between the database and the in-memory index (e.g. from a crash
+	// between Save and the next Upsert) self-heals without operator action.
+	go s.runEmbeddingIndexRebuilder()
+
+	// Periodically cluster newly embedded face markers into people, rather
+	// than at index time, since comparing every marker pairwise is too
+	// expensive to do inline for each photo.
+	go s.runFaceClusterer()
+
 	Log.Info("brains: automated scheduler started")
 	return nil
 }
 
-// Stop halts the automated scheduling.
+// Stop halts the automated scheduling, pausing any jobs that are still running
+// so they can resume from their checkpoint on the next Start.
 func (s *Scheduler) Stop() {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -86,10 +132,161 @@ func (s *Scheduler) Stop() {
 
 	s.running = false
 	s.stopChan <- true
+	s.loadSampler.Stop()
+
+	s.pauseRunningJobs()
 
 	Log.Info("brains: automated scheduler stopped")
 }
 
+// resumeInterruptedJobs scans for jobs left in the Running state and resumes
+// them from their last checkpoint instead of restarting from scratch.
+func (s *Scheduler) resumeInterruptedJobs() {
+	jobs, err := entity.FindBrainsJobsByStatus(entity.JobRunning)
+
+	if err != nil {
+		Log.Warnf("brains: failed to scan for interrupted jobs: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		Log.Infof("brains: resuming job %s from photo %d/%d", job.ID, job.Cursor, job.Total)
+		go s.runJob(job)
+	}
+}
+
+// pauseRunningJobs flushes the cursor of every running job and marks it Paused
+// so a later Start (or ResumeJob) can pick it back up.
+func (s *Scheduler) pauseRunningJobs() {
+	jobs, err := entity.FindBrainsJobsByStatus(entity.JobRunning)
+
+	if err != nil {
+		Log.Warnf("brains: failed to list running jobs on shutdown: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		job.Status = entity.JobPaused
+
+		if err := job.Save(); err != nil {
+			Log.Warnf("brains: failed to pause job %s: %v", job.ID, err)
+		}
+	}
+}
+
+// PauseJob transitions a queued or running job to Paused, flushing its cursor.
+func (s *Scheduler) PauseJob(id string) error {
+	job, err := entity.FindBrainsJob(id)
+	if err != nil {
+		return err
+	}
+
+	job.Status = entity.JobPaused
+
+	return job.Save()
+}
+
+// ResumeJob resumes a paused or failed job from its last checkpoint.
+func (s *Scheduler) ResumeJob(id string) error {
+	job, err := entity.FindBrainsJob(id)
+	if err != nil {
+		return err
+	}
+
+	if job.Status == entity.JobRunning || job.Status == entity.JobCompleted {
+		return nil
+	}
+
+	job.Status = entity.JobRunning
+	job.Error = ""
+
+	if err := job.Save(); err != nil {
+		return err
+	}
+
+	go s.runJob(job)
+
+	return nil
+}
+
+// CancelJob marks a job Failed so the scheduler stops resuming it.
+func (s *Scheduler) CancelJob(id string) error {
+	job, err := entity.FindBrainsJob(id)
+	if err != nil {
+		return err
+	}
+
+	job.Status = entity.JobFailed
+	job.Error = "canceled"
+
+	return job.Save()
+}
+
+// JobProgress describes a job's current progress and estimated time to completion.
+type JobProgress struct {
+	ID        string        `json:"id"`
+	Status    string        `json:"status"`
+	Cursor    int           `json:"cursor"`
+	Total     int           `json:"total"`
+	ETA       time.Duration `json:"eta"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// ListJobs returns progress/ETA information for all BRAINS jobs with the given status.
+// Pass an empty status to list jobs in every state.
+func (s *Scheduler) ListJobs(status string) ([]JobProgress, error) {
+	var jobs []*entity.BrainsJob
+	var err error
+
+	if status == "" {
+		for _, st := range []string{entity.JobQueued, entity.JobRunning, entity.JobPaused, entity.JobFailed, entity.JobCompleted} {
+			found, findErr := entity.FindBrainsJobsByStatus(st)
+			if findErr != nil {
+				continue
+			}
+			jobs = append(jobs, found...)
+		}
+	} else {
+		jobs, err = entity.FindBrainsJobsByStatus(status)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]JobProgress, 0, len(jobs))
+
+	for _, job := range jobs {
+		result = append(result, JobProgress{
+			ID:        job.ID,
+			Status:    job.Status,
+			Cursor:    job.Cursor,
+			Total:     job.Total,
+			ETA:       estimateETA(job),
+			UpdatedAt: job.UpdatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// estimateETA extrapolates remaining time from the average time-per-photo
+// observed so far in the job's lifetime.
+func estimateETA(job *entity.BrainsJob) time.Duration {
+	if job.Cursor <= 0 || job.Cursor >= job.Total {
+		return 0
+	}
+
+	elapsed := job.UpdatedAt.Sub(job.CreatedAt)
+	if elapsed <= 0 {
+		return 0
+	}
+
+	perPhoto := elapsed / time.Duration(job.Cursor)
+	remaining := job.Total - job.Cursor
+
+	return perPhoto * time.Duration(remaining)
+}
+
 // SetInterval changes the scheduling interval.
 func (s *Scheduler) SetInterval(interval time.Duration) {
 	s.mutex.Lock()
@@ -106,6 +303,7 @@ func (s *Scheduler) run() {
 
 	// Run once immediately on startup
 	s.scheduleBatch()
+	s.recordWatermark()
 
 	for {
 		select {
@@ -113,12 +311,28 @@ func (s *Scheduler) run() {
 			return
 		case <-ticker.C:
 			// Check for optimal timing based on system load
-			if s.isSystemIdle() {
-				Log.Debug("brains: system is idle, scheduling analysis batch")
-				s.scheduleBatch()
-			} else {
+			if !s.isSystemIdle() {
 				Log.Debug("brains: system is busy, deferring analysis")
+				continue
 			}
+
+			// Skip the tick entirely if nothing has changed since the last
+			// pass: no new results, no photo imported/edited, and no model
+			// upgrade, so idle libraries don't wake disks for nothing.
+			run, reason := s.shouldRun()
+
+			s.mutex.Lock()
+			s.lastSkipped = reason
+			s.mutex.Unlock()
+
+			if !run {
+				Log.Debugf("brains: %s, skipping", reason)
+				continue
+			}
+
+			Log.Debug("brains: system is idle, scheduling analysis batch")
+			s.scheduleBatch()
+			s.recordWatermark()
 		}
 	}
 }
@@ -134,35 +348,100 @@ func (s *Scheduler) runCollectionCurator() {
 		case <-s.stopChan:
 			return
 		case <-ticker.C:
-			// Only run collection curation during low-activity periods
-			if s.isSystemIdle() && !mutex.MainWorker.Running() {
-				Log.Info("brains: updating AI-curated collections")
-				albums, err := s.curator.CurateAllCollections()
-				if err != nil {
-					Log.Errorf("brains: error curating collections: %v", err)
-				} else {
-					Log.Infof("brains: updated %d AI-curated collections", len(albums))
-				}
+			// Only run collection curation during low-activity periods, and
+			// only if a theme was added/modified or new results were written
+			// since the last pass.
+			if !s.isSystemIdle() || mutex.MainWorker.Running() {
+				continue
+			}
+
+			curationsDirty := UpdateCurations.Swap(false)
+			resultsDirty := UpdateResults.Load()
+
+			if !curationsDirty && !resultsDirty {
+				Log.Debug("brains: no curation changes since last pass, skipping")
+				continue
+			}
+
+			Log.Info("brains: updating AI-curated collections")
+			albums, err := s.curator.CurateAllCollections()
+			if err != nil {
+				Log.Errorf("brains: error curating collections: %v", err)
+			} else {
+				Log.Infof("brains: updated %d AI-curated collections", len(albums))
+			}
+		}
+	}
+}
+
+// runEmbeddingIndexRebuilder periodically rebuilds the vector search index
+// from scratch during idle periods, run far less often than the main
+// analysis loop since a rebuild reads every BrainsResult with an embedding.
+func (s *Scheduler) runEmbeddingIndexRebuilder() {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			if !s.isSystemIdle() {
+				continue
+			}
+
+			Log.Info("brains: rebuilding embedding index")
+
+			if err := s.brains.RebuildEmbeddingIndex(); err != nil {
+				Log.Errorf("brains: error rebuilding embedding index: %v", err)
+			}
+		}
+	}
+}
+
+// runFaceClusterer periodically groups markers with a face embedding into
+// people, during idle periods and only if a new embedding was saved since
+// the last pass.
+func (s *Scheduler) runFaceClusterer() {
+	ticker := time.NewTicker(6 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			if !s.isSystemIdle() {
+				continue
+			}
+
+			if !UpdateFaces.Swap(false) {
+				Log.Debug("brains: no new face embeddings since last pass, skipping clustering")
+				continue
+			}
+
+			Log.Info("brains: clustering face markers into people")
+
+			n, err := s.faceClusterer.Cluster()
+			if err != nil {
+				Log.Errorf("brains: error clustering face markers: %v", err)
+			} else {
+				Log.Infof("brains: clustered %d face markers", n)
 			}
 		}
 	}
 }
 
-// isSystemIdle determines if the system is idle enough for background processing.
+// isSystemIdle determines if the system is idle enough for background
+// processing, based on the sampled moving-average CPU idle percentage
+// rather than a time-of-day heuristic.
 func (s *Scheduler) isSystemIdle() bool {
-	// Avoid running when indexing or importing is active
+	// Defer entirely while indexing, importing, or sharing is active.
 	if mutex.MainWorker.Running() || mutex.ImportWorker.Running() || mutex.ShareWorker.Running() {
 		return false
 	}
 
-	// TODO: Add actual CPU idle percentage check
-	// For now, assume system is idle during night hours (1am to 5am)
-	hour := time.Now().Hour()
-	if hour >= 1 && hour <= 5 {
-		return true
-	}
-
-	return true
+	return s.loadSampler.IdlePercent() >= s.idleCPUTarget
 }
 
 // scheduleBatch finds unprocessed photos and schedules them for analysis.
@@ -183,12 +462,156 @@ func (s *Scheduler) scheduleBatch() {
 
 	// Create a task
 	task := entity.NewTask(entity.TaskBrainsAnalyze, "automated brains analysis", entity.TaskPriorityBackground)
-	
+
 	// This would use a global task manager in the real application
 	event.Publish("tasks", event.TaskCreate, task)
 
+	// Persist the batch as a first-class, resumable job before starting it, so
+	// a container restart mid-analysis resumes from the checkpoint instead of
+	// losing all work.
+	job := entity.NewBrainsJob(unprocessedIDs)
+	job.Status = entity.JobRunning
+
+	if err := job.Save(); err != nil {
+		Log.Errorf("brains: error persisting job: %v", err)
+		return
+	}
+
 	// Process photos in the background
-	go s.processPhotoBatch(unprocessedIDs)
+	go s.runJob(job)
+}
+
+// runJob processes a persisted job's remaining photos starting at its cursor,
+// checkpointing after every photo so it can resume across restarts. Photos
+// are processed in sub-batches sized by the adaptive BatchController, with a
+// short yield between sub-batches so the controller can react to changing
+// system load and foreground workers get a chance to run.
+func (s *Scheduler) runJob(job *entity.BrainsJob) {
+	s.busy.Store(true)
+	defer s.busy.Store(false)
+
+	photos := job.Photos()
+
+	for job.Cursor < len(photos) {
+		// Defer entirely while a foreground worker needs the resources.
+		if mutex.MainWorker.Running() || mutex.ImportWorker.Running() || mutex.ShareWorker.Running() {
+			time.Sleep(loadSampleInterval)
+			continue
+		}
+
+		subBatchSize := s.batchCtrl.Adjust()
+		end := job.Cursor + subBatchSize
+		if end > len(photos) {
+			end = len(photos)
+		}
+
+		for _, id := range photos[job.Cursor:end] {
+			if photo := entity.FindPhoto(id, s.query.Db()); photo != nil {
+				if filePath, err := s.brains.SelectInputPath(photo); err != nil {
+					Log.Warnf("brains: job %s found no input file for photo %s: %v", job.ID, id, err)
+				} else if _, err := s.brains.ProcessFile(filePath); err != nil {
+					Log.Warnf("brains: job %s failed on photo %s: %v", job.ID, id, err)
+				}
+			}
+
+			job.Cursor++
+		}
+
+		if err := job.Save(); err != nil {
+			Log.Errorf("brains: job %s failed to checkpoint: %v", job.ID, err)
+		}
+
+		// Re-read status so PauseJob/CancelJob take effect between sub-batches.
+		if current, err := entity.FindBrainsJob(job.ID); err == nil && current.Status != entity.JobRunning {
+			Log.Infof("brains: job %s %s at photo %d/%d", job.ID, current.Status, job.Cursor, job.Total)
+			return
+		}
+
+		// Yield between sub-batches so the load sampler's next reading
+		// reflects this job's own impact before growing the batch further.
+		if job.Cursor < len(photos) {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	now := time.Now()
+	job.Status = entity.JobCompleted
+	job.CompletedAt = &now
+
+	if err := job.Save(); err != nil {
+		Log.Errorf("brains: job %s failed to mark completed: %v", job.ID, err)
+	}
+
+	Log.Infof("brains: job %s completed, processed %d photos", job.ID, job.Total)
+}
+
+// shouldRun reports whether the scheduler has anything to do: results were
+// written, a photo was imported or edited, or a model was upgraded since
+// the watermark recorded after the last completed run. When it returns
+// false, reason explains why the tick is being skipped.
+func (s *Scheduler) shouldRun() (run bool, reason string) {
+	if UpdateResults.Load() {
+		return true, ""
+	}
+
+	latestPhoto, err := s.latestPhotoUpdate()
+	if err != nil {
+		Log.Warnf("brains: failed to read latest photo watermark, running anyway: %v", err)
+		return true, ""
+	}
+
+	fingerprint := s.brains.ModelFingerprint()
+
+	if fingerprint != s.state.ModelFingerprint {
+		return true, ""
+	}
+
+	if latestPhoto.After(s.state.LastPhotoUpdatedAt) {
+		return true, ""
+	}
+
+	return false, "no photo changes or model updates since last run"
+}
+
+// latestPhotoUpdate returns the most recent UpdatedAt among non-deleted
+// photos, used as the watermark to detect imports/edits since the last run.
+func (s *Scheduler) latestPhotoUpdate() (time.Time, error) {
+	var updatedAt sql.NullTime
+
+	err := s.query.Db().Raw(`
+		SELECT MAX(updated_at) FROM photos WHERE deleted_at IS NULL
+	`).Row().Scan(&updatedAt)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if !updatedAt.Valid {
+		return time.Time{}, nil
+	}
+
+	return updatedAt.Time, nil
+}
+
+// recordWatermark persists the photo and model watermarks for the run that
+// just completed, and clears the dirty flag scheduleBatch's SQL scan
+// already accounted for.
+func (s *Scheduler) recordWatermark() {
+	UpdateResults.Store(false)
+
+	latestPhoto, err := s.latestPhotoUpdate()
+	if err != nil {
+		Log.Warnf("brains: failed to read latest photo watermark: %v", err)
+		return
+	}
+
+	s.state.LastPhotoUpdatedAt = latestPhoto
+	s.state.ModelFingerprint = s.brains.ModelFingerprint()
+	s.state.LastRunAt = time.Now()
+
+	if err := s.state.Save(); err != nil {
+		Log.Warnf("brains: failed to persist scheduler watermark: %v", err)
+	}
 }
 
 // findUnprocessedPhotos finds photos that have not been analyzed with BRAINS yet.
@@ -208,50 +631,21 @@ func (s *Scheduler) findUnprocessedPhotos(limit int) ([]string, error) {
 	return photoIDs, err
 }
 
-// processPhotoBatch processes a batch of photos with BRAINS.
-func (s *Scheduler) processPhotoBatch(photoIDs []string) {
-	if len(photoIDs) == 0 {
-		return
-	}
-
-	// Convert photo IDs to file paths
-	var filePaths []string
-	for _, id := range photoIDs {
-		photo := entity.FindPhoto(id, s.query.Db())
-		if photo == nil {
-			continue
-		}
-
-		if filename := photo.FileName(); filename != "" {
-			filePath := s.brains.conf.OriginalsPath() + "/" + filename
-			filePaths = append(filePaths, filePath)
-		}
-	}
-
-	if len(filePaths) == 0 {
-		return
-	}
-
-	// Process the files
-	results, err := s.brains.ProcessFiles(filePaths)
-	
-	if err != nil {
-		Log.Errorf("brains: error processing batch: %v", err)
-		return
-	}
-	
-	Log.Infof("brains: successfully processed %d photos", len(results.Files))
-}
-
 // GetInfo returns information about the scheduler status.
 func (s *Scheduler) GetInfo() map[string]interface{} {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	
 	return map[string]interface{}{
-		"running":        s.running,
-		"interval":       s.interval.String(),
-		"max_batch_size": s.maxBatchSize,
-		"idle_target":    s.idleCPUTarget,
+		"running":               s.running,
+		"interval":              s.interval.String(),
+		"max_batch_size":        s.maxBatchSize,
+		"idle_target":           s.idleCPUTarget,
+		"idle_percent":          s.loadSampler.IdlePercent(),
+		"current_batch":         s.batchCtrl.Current(),
+		"last_skipped":          s.lastSkipped,
+		"last_run_at":           s.state.LastRunAt,
+		"last_photo_updated_at": s.state.LastPhotoUpdatedAt,
+		"model_fingerprint":     s.state.ModelFingerprint,
 	}
 }
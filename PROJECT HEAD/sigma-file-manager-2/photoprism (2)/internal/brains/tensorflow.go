@@ -1,34 +1,47 @@
 package brains
 
 import (
+	"context"
 	"fmt"
+	"image"
 	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	tf "github.com/tensorflow/tensorflow/tensorflow/go"
 	"github.com/tensorflow/tensorflow/tensorflow/go/op"
+
+	"github.com/photoprism/photoprism/internal/config"
 )
 
 // TensorFlowModel represents a loaded TensorFlow model ready for inference.
 type TensorFlowModel struct {
-	model     *tf.SavedModel
-	modelPath string
-	modelType string
-	version   string
-	mutex     sync.RWMutex
-	inputName string
+	model      *tf.SavedModel
+	modelPath  string
+	modelType  string
+	version    string
+	conf       *config.Config
+	mutex      sync.RWMutex
+	inputName  string
 	outputName string
-	loaded    bool
+	loaded     bool
+	healthy    atomic.Bool
 }
 
-// NewTensorFlowModel creates a new TensorFlow model instance.
-func NewTensorFlowModel(modelPath, modelType string) *TensorFlowModel {
+// NewTensorFlowModel creates a new TensorFlow model instance. conf may be
+// nil, in which case Load falls back to the default (CPU, unrestricted
+// memory) SessionOptions.
+func NewTensorFlowModel(modelPath, modelType string, conf *config.Config) *TensorFlowModel {
 	return &TensorFlowModel{
 		modelPath:  modelPath,
 		modelType:  modelType,
+		conf:       conf,
 		inputName:  "input:0",
 		outputName: "output:0",
 		loaded:     false,
@@ -59,14 +72,17 @@ func (m *TensorFlowModel) Load() error {
 		return fmt.Errorf("tensorflow: model file not found: %s", m.modelPath)
 	}
 
-	// Load the saved model
-	model, err := tf.LoadSavedModel(m.modelPath, []string{"serve"}, nil)
+	// Load the saved model, honoring PHOTOPRISM_BRAINS_DEVICE and the
+	// configured GPU memory fraction instead of the default (CPU-only,
+	// unrestricted) session options.
+	model, err := tf.LoadSavedModel(m.modelPath, []string{"serve"}, buildSessionOptions(m.conf))
 	if err != nil {
 		return fmt.Errorf("tensorflow: failed to load model: %v", err)
 	}
 
 	m.model = model
 	m.loaded = true
+	m.healthy.Store(true)
 
 	// Output memory statistics in debug mode
 	var stats runtime.MemStats
@@ -76,6 +92,17 @@ func (m *TensorFlowModel) Load() error {
 	return nil
 }
 
+// Healthy reports whether the model is loaded and its last inference call,
+// if any, succeeded. ModelManager's health-check loop reloads any model
+// that reports false.
+func (m *TensorFlowModel) Healthy() bool {
+	m.mutex.RLock()
+	loaded := m.loaded
+	m.mutex.RUnlock()
+
+	return loaded && m.healthy.Load()
+}
+
 // Close releases the TensorFlow model resources.
 func (m *TensorFlowModel) Close() error {
 	m.mutex.Lock()
@@ -116,16 +143,115 @@ func (m *TensorFlowModel) Predict(inputTensor *tf.Tensor) (*tf.Tensor, error) {
 		nil,
 	)
 	if err != nil {
+		m.healthy.Store(false)
 		return nil, fmt.Errorf("tensorflow: failed to run inference: %v", err)
 	}
 
 	if len(output) == 0 {
+		m.healthy.Store(false)
 		return nil, fmt.Errorf("tensorflow: no output produced")
 	}
 
 	return output[0], nil
 }
 
+// buildSessionOptions constructs TensorFlow SessionOptions honoring
+// conf.BrainsDevice() (e.g. "/gpu:0", "/cpu:0") and
+// conf.BrainsGPUMemoryFraction(), so CUDA-enabled TensorFlow builds can
+// actually use the GPU instead of the nil options LoadSavedModel used to
+// receive.
+func buildSessionOptions(conf *config.Config) *tf.SessionOptions {
+	if conf == nil {
+		return &tf.SessionOptions{}
+	}
+
+	device := strings.TrimSpace(conf.BrainsDevice())
+	fraction := conf.BrainsGPUMemoryFraction()
+
+	var gpuOptions []byte
+	if fraction > 0 {
+		gpuOptions = append(gpuOptions, protoDouble(1, fraction)...)
+	}
+	if index, ok := gpuDeviceIndex(device); ok {
+		gpuOptions = append(gpuOptions, protoString(5, index)...)
+	}
+
+	var config []byte
+	if strings.HasPrefix(device, "/cpu") {
+		// Force CPU-only execution by reporting zero available GPUs.
+		config = append(config, protoMessage(1, protoInt32MapEntry("GPU", 0))...)
+	}
+	if len(gpuOptions) > 0 {
+		config = append(config, protoMessage(6, gpuOptions)...)
+	}
+
+	return &tf.SessionOptions{Config: config}
+}
+
+// gpuDeviceIndex extracts the device index from a "/gpu:N" spec for
+// GPUOptions.visible_device_list.
+func gpuDeviceIndex(device string) (string, bool) {
+	if !strings.HasPrefix(device, "/gpu:") {
+		return "", false
+	}
+
+	return strings.TrimPrefix(device, "/gpu:"), true
+}
+
+// The helpers below hand-encode the handful of ConfigProto/GPUOptions
+// fields buildSessionOptions needs, in protobuf wire format, to avoid
+// pulling in the full TensorFlow proto package for three scalar fields.
+
+func protoVarint(buf []byte, v uint64) []byte {
+	for v >= 0x80 {
+		buf = append(buf, byte(v)|0x80)
+		v >>= 7
+	}
+
+	return append(buf, byte(v))
+}
+
+func protoTag(fieldNum, wireType int) []byte {
+	return protoVarint(nil, uint64(fieldNum<<3|wireType))
+}
+
+func protoString(fieldNum int, s string) []byte {
+	b := protoTag(fieldNum, 2)
+	b = protoVarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func protoDouble(fieldNum int, v float64) []byte {
+	b := protoTag(fieldNum, 1)
+	bits := math.Float64bits(v)
+
+	for i := 0; i < 8; i++ {
+		b = append(b, byte(bits>>(8*i)))
+	}
+
+	return b
+}
+
+func protoVarintField(fieldNum int, v uint64) []byte {
+	b := protoTag(fieldNum, 0)
+	return protoVarint(b, v)
+}
+
+func protoMessage(fieldNum int, payload []byte) []byte {
+	b := protoTag(fieldNum, 2)
+	b = protoVarint(b, uint64(len(payload)))
+	return append(b, payload...)
+}
+
+// protoInt32MapEntry encodes a single map<string, int32> entry message
+// (ConfigProto.device_count's element type), combining a string key (field
+// 1) and an int32 value (field 2).
+func protoInt32MapEntry(key string, value int32) []byte {
+	entry := protoString(1, key)
+	entry = append(entry, protoVarintField(2, uint64(value))...)
+	return entry
+}
+
 // PreprocessImage converts an image file to a tensor suitable for TensorFlow input.
 func PreprocessImage(imagePath string, width, height int) (*tf.Tensor, error) {
 	// Read file contents
@@ -196,17 +322,59 @@ func constructGraphToNormalizeImage(width, height int) (*tf.Graph, tf.Output, tf
 	return graph, input, expanded, nil
 }
 
+// imageTensor resizes img to width x height using nearest-neighbor sampling
+// and returns a tensor shaped [1, height, width, 3] with channel values
+// scaled to [0, 1], the input format the processors in processor.go feed to
+// their models.
+func imageTensor(img image.Image, width, height int) (*tf.Tensor, error) {
+	rgb := resizeRGB(img, img.Bounds(), width, height)
+
+	pixels := make([][][]float32, height)
+	i := 0
+	for y := 0; y < height; y++ {
+		row := make([][]float32, width)
+		for x := 0; x < width; x++ {
+			px := make([]float32, 3)
+			for c := 0; c < 3; c++ {
+				px[c] = float32(rgb[i]) / 255
+				i++
+			}
+			row[x] = px
+		}
+		pixels[y] = row
+	}
+
+	tensor, err := tf.NewTensor([][][][]float32{pixels})
+	if err != nil {
+		return nil, fmt.Errorf("brains: failed to build input tensor: %v", err)
+	}
+
+	return tensor, nil
+}
+
+// modelHealthCheckInterval is how often a ModelManager's background
+// goroutine checks whether its loaded models are still healthy.
+const modelHealthCheckInterval = 5 * time.Minute
+
 // ModelManager handles the loading and lifecycle of TensorFlow models.
 type ModelManager struct {
 	models map[string]*TensorFlowModel
 	mutex  sync.RWMutex
+	conf   *config.Config
 }
 
-// NewModelManager creates a new model manager.
-func NewModelManager() *ModelManager {
-	return &ModelManager{
+// NewModelManager creates a new model manager and starts its background
+// health-check loop, which reloads any model whose session has become
+// invalid since it was last used.
+func NewModelManager(conf *config.Config) *ModelManager {
+	mm := &ModelManager{
 		models: make(map[string]*TensorFlowModel),
+		conf:   conf,
 	}
+
+	go mm.healthCheckLoop()
+
+	return mm
 }
 
 // GetModel returns a loaded model by type, loading it if necessary.
@@ -216,22 +384,89 @@ func (mm *ModelManager) GetModel(modelPath, modelType string) (*TensorFlowModel,
 
 	key := modelType
 	model, exists := mm.models[key]
-	
+
 	if !exists {
-		model = NewTensorFlowModel(modelPath, modelType)
+		model = NewTensorFlowModel(modelPath, modelType, mm.conf)
 		mm.models[key] = model
 	}
-	
+
 	if !model.loaded {
 		if err := model.Load(); err != nil {
 			delete(mm.models, key)
 			return nil, err
 		}
 	}
-	
+
 	return model, nil
 }
 
+// ModelWarmupSpec pairs a model's file path with the type key GetModel
+// caches it under.
+type ModelWarmupSpec struct {
+	Path string
+	Type string
+}
+
+// Warmup loads every spec's model in parallel, so GetModel's first real
+// call during indexing doesn't pay the load cost. Failures are logged
+// rather than returned, since a model that's still missing or corrupt
+// should fail per-file later rather than abort BRAINS Init.
+func (mm *ModelManager) Warmup(ctx context.Context, specs []ModelWarmupSpec) {
+	var wg sync.WaitGroup
+
+	for _, spec := range specs {
+		wg.Add(1)
+
+		go func(spec ModelWarmupSpec) {
+			defer wg.Done()
+
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			if _, err := mm.GetModel(spec.Path, spec.Type); err != nil {
+				Log.Warnf("tensorflow: warmup failed for %s model: %v", spec.Type, err)
+			}
+		}(spec)
+	}
+
+	wg.Wait()
+}
+
+// healthCheckLoop periodically reloads any model that's stopped reporting
+// healthy, until the process exits.
+func (mm *ModelManager) healthCheckLoop() {
+	ticker := time.NewTicker(modelHealthCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		mm.reloadUnhealthy()
+	}
+}
+
+// reloadUnhealthy closes and forgets every model that's failed its last
+// inference call, so the next GetModel call reloads it from disk.
+func (mm *ModelManager) reloadUnhealthy() {
+	mm.mutex.Lock()
+	defer mm.mutex.Unlock()
+
+	for key, model := range mm.models {
+		if model.Healthy() {
+			continue
+		}
+
+		Log.Warnf("tensorflow: %s model session unhealthy, reloading", key)
+
+		if err := model.Close(); err != nil {
+			Log.Warnf("tensorflow: failed to close unhealthy %s model: %v", key, err)
+		}
+
+		delete(mm.models, key)
+	}
+}
+
 // CloseAll closes all loaded models.
 func (mm *ModelManager) CloseAll() {
 	mm.mutex.Lock()
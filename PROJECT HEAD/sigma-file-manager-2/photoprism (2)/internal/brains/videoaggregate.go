@@ -0,0 +1,125 @@
+package brains
+
+import "sort"
+
+// aggregateFrameResults folds the per-keyframe results in frames into a
+// single FileResult for path: AestheticResult keeps the frame with the
+// highest Score (max-pooling), ObjectResult unions every frame's
+// detections, keeping only the highest-confidence instance of each label,
+// and SceneResult keeps the frame whose SceneType is the majority vote
+// across frames. Any other result type is kept from the first frame that
+// produced one, since it isn't a processor processVideo knows how to pool.
+func aggregateFrameResults(path string, frames []*FileResult) *FileResult {
+	merged := NewFileResult(path)
+
+	var aesthetics []AestheticResult
+	var objectSets []ObjectResult
+	var scenes []SceneResult
+	other := make(map[string]ProcessorResult)
+
+	for _, frame := range frames {
+		if frame == nil {
+			continue
+		}
+
+		for name, result := range frame.Results {
+			switch r := result.(type) {
+			case AestheticResult:
+				aesthetics = append(aesthetics, r)
+			case ObjectResult:
+				objectSets = append(objectSets, r)
+			case SceneResult:
+				scenes = append(scenes, r)
+			default:
+				if _, exists := other[name]; !exists {
+					other[name] = result
+				}
+			}
+		}
+	}
+
+	if len(aesthetics) > 0 {
+		merged.Results["aesthetic"] = maxAesthetic(aesthetics)
+	}
+
+	if len(objectSets) > 0 {
+		merged.Results["object"] = unionObjects(objectSets)
+	}
+
+	if len(scenes) > 0 {
+		merged.Results["scene"] = majorityScene(scenes)
+	}
+
+	for name, result := range other {
+		merged.Results[name] = result
+	}
+
+	return merged
+}
+
+// maxAesthetic returns the result with the highest Score, so a single dull
+// frame doesn't drag down an otherwise well-composed video.
+func maxAesthetic(results []AestheticResult) AestheticResult {
+	best := results[0]
+
+	for _, r := range results[1:] {
+		if r.Score > best.Score {
+			best = r
+		}
+	}
+
+	return best
+}
+
+// unionObjects merges every frame's detections into one list, keeping only
+// the highest-confidence detection per label so the same object spotted
+// across several frames isn't reported once per frame.
+func unionObjects(results []ObjectResult) ObjectResult {
+	best := make(map[string]DetectedObject)
+
+	for _, r := range results {
+		for _, obj := range r.Objects {
+			if existing, ok := best[obj.Label]; !ok || obj.Confidence > existing.Confidence {
+				best[obj.Label] = obj
+			}
+		}
+	}
+
+	objects := make([]DetectedObject, 0, len(best))
+	for _, obj := range best {
+		objects = append(objects, obj)
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Confidence > objects[j].Confidence
+	})
+
+	return ObjectResult{Objects: objects}
+}
+
+// majorityScene returns the result whose SceneType occurs most often
+// across results, so a single outlier frame doesn't override the dominant
+// scene. Ties are broken in favor of whichever scene type was seen first.
+func majorityScene(results []SceneResult) SceneResult {
+	counts := make(map[string]int)
+	first := make(map[string]SceneResult)
+
+	for _, r := range results {
+		counts[r.SceneType]++
+		if _, ok := first[r.SceneType]; !ok {
+			first[r.SceneType] = r
+		}
+	}
+
+	var winner string
+	var winnerCount int
+
+	for _, r := range results {
+		if count := counts[r.SceneType]; count > winnerCount {
+			winner = r.SceneType
+			winnerCount = count
+		}
+	}
+
+	return first[winner]
+}
@@ -0,0 +1,185 @@
+package brains
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/photoprism/photoprism/internal/entity"
+)
+
+// warmerQueueSize bounds how many pending photo IDs CacheWarmer buffers
+// before Warm starts blocking its caller.
+const warmerQueueSize = 1000
+
+// warmerBackoff is how long a worker waits before rechecking whether the
+// scheduler is still busy.
+const warmerBackoff = 2 * time.Second
+
+// calculateOptimalWorkers determines the worker pool size from the number of
+// CPU cores, the same 75%-of-cores heuristic Convert.Start uses to size its
+// ConvertJob worker pool, capped at maxWorkers.
+func calculateOptimalWorkers(maxWorkers int) int {
+	if maxWorkers <= 0 {
+		maxWorkers = 1
+	}
+
+	optimalWorkers := int(float64(runtime.NumCPU()) * 0.75)
+
+	if optimalWorkers < 1 {
+		optimalWorkers = 1
+	}
+
+	if optimalWorkers > maxWorkers {
+		optimalWorkers = maxWorkers
+	}
+
+	return optimalWorkers
+}
+
+// CacheWarmer pre-computes and caches BRAINS results for newly indexed or
+// modified photos in the background, so by the time a user opens the photo
+// detail view, aesthetic/scene/object results are already available instead
+// of being computed on request. It's analogous to an artwork cache warmer:
+// the scanner pushes photo IDs it just touched, and a small worker pool
+// drains them through Brains.ProcessFile at its own pace.
+type CacheWarmer struct {
+	brains   *Brains
+	queue    chan string
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	workers  int
+}
+
+// NewCacheWarmer returns a CacheWarmer for b, sized the same way
+// Convert.Start sizes its worker pool (CalculateOptimalWorkers against
+// conf.IndexWorkers), and starts its worker pool.
+func NewCacheWarmer(b *Brains) *CacheWarmer {
+	w := &CacheWarmer{
+		brains:   b,
+		queue:    make(chan string, warmerQueueSize),
+		stopChan: make(chan struct{}),
+		workers:  calculateOptimalWorkers(b.conf.IndexWorkers()),
+	}
+
+	for i := 0; i < w.workers; i++ {
+		go w.work()
+	}
+
+	return w
+}
+
+// Warm enqueues photoIDs to be processed in the background. It blocks only
+// if the queue is full or ctx is canceled first, so a scanner pass can
+// treat it as fire-and-forget under normal load. Submissions made after
+// Shutdown are dropped.
+func (w *CacheWarmer) Warm(ctx context.Context, photoIDs []string) {
+	for _, id := range photoIDs {
+		select {
+		case <-w.stopChan:
+			return
+		default:
+		}
+
+		w.wg.Add(1)
+
+		select {
+		case w.queue <- id:
+		case <-ctx.Done():
+			w.wg.Done()
+			return
+		case <-w.stopChan:
+			w.wg.Done()
+			return
+		}
+	}
+}
+
+// Shutdown stops the worker pool from accepting further cache-warming
+// requests and waits for every already-queued photo ID to finish
+// processing, or for ctx to be canceled first, whichever happens sooner.
+func (w *CacheWarmer) Shutdown(ctx context.Context) error {
+	close(w.stopChan)
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush blocks until every photo ID enqueued so far has finished
+// processing, for callers (e.g. a manual "reprocess" endpoint) that need to
+// wait for warmed results before responding.
+func (w *CacheWarmer) Flush() {
+	w.wg.Wait()
+}
+
+// work drains the queue, processing one photo at a time and backing off
+// while the scheduler is actively running a job to avoid contending with
+// it for CPU and disk. Once Shutdown closes stopChan, it keeps draining
+// anything already queued before exiting, so in-flight work isn't dropped.
+func (w *CacheWarmer) work() {
+	for {
+		select {
+		case id := <-w.queue:
+			w.runJob(id)
+			continue
+		default:
+		}
+
+		select {
+		case id := <-w.queue:
+			w.runJob(id)
+		case <-w.stopChan:
+			return
+		}
+	}
+}
+
+// runJob processes a single queued photo ID, backing off while the
+// scheduler is busy, and marks it done on the wait group either way.
+func (w *CacheWarmer) runJob(id string) {
+	for w.brains.schedulerBusy() {
+		time.Sleep(warmerBackoff)
+	}
+
+	w.processPhoto(id)
+	w.wg.Done()
+}
+
+// processPhoto resolves a photo ID to its input file and warms the BRAINS
+// cache for it, logging rather than failing on a missing photo or input
+// file since this runs opportunistically in the background.
+func (w *CacheWarmer) processPhoto(id string) {
+	if !w.brains.initialized {
+		if err := w.brains.Init(); err != nil {
+			Log.Warnf("brains: cache warmer failed to initialize: %v", err)
+			return
+		}
+	}
+
+	photo := entity.FindPhoto(id, w.brains.query.Db())
+	if photo == nil {
+		Log.Warnf("brains: cache warmer found no photo for id %s", id)
+		return
+	}
+
+	filePath, err := w.brains.SelectInputPath(photo)
+	if err != nil {
+		Log.Warnf("brains: cache warmer found no input file for photo %s: %v", id, err)
+		return
+	}
+
+	if _, err := w.brains.ProcessFile(filePath); err != nil {
+		Log.Warnf("brains: cache warmer failed on photo %s: %v", id, err)
+	}
+}
@@ -0,0 +1,282 @@
+package brains
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/photoprism/photoprism/internal/entity"
+)
+
+// brainsYamlExt is the suffix SaveBrainsResultAsYaml and
+// LoadBrainsResultsFromYaml use for BRAINS sidecars, so they live alongside
+// but never collide with photoprism's own "<basename>.yml" metadata
+// sidecars.
+const brainsYamlExt = ".brains.yml"
+
+// brainsResultYaml is the on-disk shape of a BRAINS sidecar. It mirrors
+// entity.BrainsResult plus the fields a bare BrainsResult row can't hold on
+// its own: decoded objects, decoded emotions, split keywords, the model
+// versions that produced them, and the original file name (with extension)
+// so importSidecar can look the photo back up by query.PhotoByName the same
+// way every other caller does.
+type brainsResultYaml struct {
+	FileName       string                   `yaml:"FileName"`
+	AestheticScore float32                  `yaml:"AestheticScore"`
+	Composition    float32                  `yaml:"Composition"`
+	Contrast       float32                  `yaml:"Contrast"`
+	Exposure       float32                  `yaml:"Exposure"`
+	ColorHarmony   float32                  `yaml:"ColorHarmony"`
+	SceneType      string                   `yaml:"SceneType,omitempty"`
+	IndoorOutdoor  string                   `yaml:"IndoorOutdoor,omitempty"`
+	TimeOfDay      string                   `yaml:"TimeOfDay,omitempty"`
+	Weather        string                   `yaml:"Weather,omitempty"`
+	Objects        []brainsResultYamlObject `yaml:"Objects,omitempty"`
+	Emotions       map[string]float32       `yaml:"Emotions,omitempty"`
+	Keywords       []string                 `yaml:"Keywords,omitempty"`
+	ModelVersions  map[string]string        `yaml:"ModelVersions,omitempty"`
+	ProcessedAt    time.Time                `yaml:"ProcessedAt,omitempty"`
+}
+
+// brainsResultYamlObject is one detected object entry in a BRAINS sidecar.
+type brainsResultYamlObject struct {
+	Label      string  `yaml:"Label"`
+	Confidence float32 `yaml:"Confidence"`
+	X          int     `yaml:"X,omitempty"`
+	Y          int     `yaml:"Y,omitempty"`
+	Width      int     `yaml:"Width,omitempty"`
+	Height     int     `yaml:"Height,omitempty"`
+}
+
+// sidecarPath returns the path SaveBrainsResultAsYaml writes p's BRAINS
+// sidecar to, mirroring p's originals-relative directory structure under
+// conf.SidecarPath() the way photoprism's own YAML sidecars already do.
+func (b *Brains) sidecarPath(p entity.Photo) (string, error) {
+	file, err := p.PrimaryFile()
+	if err != nil {
+		return "", err
+	}
+
+	fileName := file.FileName()
+	if fileName == "" {
+		return "", fmt.Errorf("brains: photo %s has no file name", p.PhotoUID)
+	}
+
+	base := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+
+	return filepath.Join(b.conf.SidecarPath(), base+brainsYamlExt), nil
+}
+
+// SaveBrainsResultAsYaml writes r as a YAML sidecar for p, following the
+// SavePhotoAsYaml pattern photoprism already uses for its own metadata: the
+// sidecar becomes a durable, human-readable copy of the analysis that
+// survives a database wipe. It's a no-op unless conf.BackupYaml() is true.
+func (b *Brains) SaveBrainsResultAsYaml(p entity.Photo, r entity.BrainsResult) error {
+	if !b.conf.BackupYaml() {
+		return nil
+	}
+
+	path, err := b.sidecarPath(p)
+	if err != nil {
+		return err
+	}
+
+	file, err := p.PrimaryFile()
+	if err != nil {
+		return err
+	}
+
+	sidecar := brainsResultYaml{
+		FileName:       file.FileName(),
+		AestheticScore: r.AestheticScore,
+		Composition:    r.Composition,
+		Contrast:       r.Contrast,
+		Exposure:       r.Exposure,
+		ColorHarmony:   r.ColorHarmony,
+		SceneType:      r.SceneType,
+		IndoorOutdoor:  r.IndoorOutdoor,
+		TimeOfDay:      r.TimeOfDay,
+		Weather:        r.Weather,
+		Objects:        decodeBrainsResultObjects(r.ObjectResults),
+		ModelVersions:  b.modelVersions,
+	}
+
+	if r.KeywordsSorted != "" {
+		sidecar.Keywords = strings.Split(r.KeywordsSorted, ",")
+	}
+
+	if r.Emotions != "" {
+		_ = json.Unmarshal([]byte(r.Emotions), &sidecar.Emotions)
+	}
+
+	if r.ProcessedAt.Valid {
+		sidecar.ProcessedAt = r.ProcessedAt.Time
+	}
+
+	data, err := yaml.Marshal(sidecar)
+	if err != nil {
+		return fmt.Errorf("brains: failed to encode sidecar for %s: %v", p.PhotoUID, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// decodeBrainsResultObjects decodes a BrainsResult.ObjectResults blob into
+// its YAML sidecar shape, ignoring malformed or empty input rather than
+// failing the whole sidecar over one processor's bad output.
+func decodeBrainsResultObjects(objectsJSON string) []brainsResultYamlObject {
+	if objectsJSON == "" {
+		return nil
+	}
+
+	var decoded []DetectedObject
+
+	if err := json.Unmarshal([]byte(objectsJSON), &decoded); err != nil {
+		return nil
+	}
+
+	objects := make([]brainsResultYamlObject, 0, len(decoded))
+
+	for _, o := range decoded {
+		objects = append(objects, brainsResultYamlObject{
+			Label:      o.Label,
+			Confidence: o.Confidence,
+			X:          o.X,
+			Y:          o.Y,
+			Width:      o.Width,
+			Height:     o.Height,
+		})
+	}
+
+	return objects
+}
+
+// LoadBrainsResultsFromYaml walks conf.SidecarPath() for BRAINS sidecars and
+// re-imports each one into its BrainsResult row, so analysis produced before
+// a database wipe doesn't require reprocessing every photo on the next
+// rescan. It returns the number of sidecars successfully imported.
+func (b *Brains) LoadBrainsResultsFromYaml() (imported int, err error) {
+	root := b.conf.SidecarPath()
+
+	walkErr := filepath.Walk(root, func(path string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if info.IsDir() || !strings.HasSuffix(path, brainsYamlExt) {
+			return nil
+		}
+
+		if b.importSidecar(path) {
+			imported++
+		}
+
+		return nil
+	})
+
+	if walkErr != nil && !os.IsNotExist(walkErr) {
+		return imported, walkErr
+	}
+
+	return imported, nil
+}
+
+// importSidecar re-imports a single BRAINS sidecar found at path, logging
+// and skipping it on any error so one corrupt or orphaned file doesn't abort
+// the whole rescan.
+func (b *Brains) importSidecar(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		Log.Warnf("brains: failed to read sidecar %s: %v", path, err)
+		return false
+	}
+
+	var sidecar brainsResultYaml
+
+	if err := yaml.Unmarshal(data, &sidecar); err != nil {
+		Log.Warnf("brains: failed to parse sidecar %s: %v", path, err)
+		return false
+	}
+
+	if sidecar.FileName == "" {
+		Log.Warnf("brains: sidecar %s has no recorded file name, skipping", path)
+		return false
+	}
+
+	photo, err := b.query.PhotoByName(sidecar.FileName)
+	if err != nil {
+		Log.Warnf("brains: no photo found for sidecar %s: %v", path, err)
+		return false
+	}
+
+	result, err := entity.GetOrCreateBrainsResult(photo.ID)
+	if err != nil {
+		Log.Warnf("brains: failed to get/create result for sidecar %s: %v", path, err)
+		return false
+	}
+
+	result.AestheticScore = sidecar.AestheticScore
+	result.Composition = sidecar.Composition
+	result.Contrast = sidecar.Contrast
+	result.Exposure = sidecar.Exposure
+	result.ColorHarmony = sidecar.ColorHarmony
+	result.SceneType = sidecar.SceneType
+	result.IndoorOutdoor = sidecar.IndoorOutdoor
+	result.TimeOfDay = sidecar.TimeOfDay
+	result.Weather = sidecar.Weather
+	result.KeywordsSorted = strings.Join(sidecar.Keywords, ",")
+
+	if len(sidecar.Emotions) > 0 {
+		if encoded, err := json.Marshal(sidecar.Emotions); err == nil {
+			result.Emotions = string(encoded)
+		}
+	}
+
+	if len(sidecar.Objects) > 0 {
+		objects := make([]DetectedObject, 0, len(sidecar.Objects))
+
+		for _, o := range sidecar.Objects {
+			objects = append(objects, DetectedObject{
+				Label:      o.Label,
+				Confidence: o.Confidence,
+				X:          o.X,
+				Y:          o.Y,
+				Width:      o.Width,
+				Height:     o.Height,
+			})
+		}
+
+		if encoded, err := json.Marshal(objects); err == nil {
+			result.ObjectResults = string(encoded)
+		}
+	}
+
+	if !sidecar.ProcessedAt.IsZero() {
+		result.ProcessedAt = sql.NullTime{Time: sidecar.ProcessedAt, Valid: true}
+	}
+
+	if err := result.Save(); err != nil {
+		Log.Warnf("brains: failed to save result imported from %s: %v", path, err)
+		return false
+	}
+
+	if err := entity.SyncBrainsObjects(photo.ID, result.ObjectResults); err != nil {
+		Log.Warnf("brains: failed to sync object index for %s: %v", photo.ID, err)
+	}
+
+	if err := entity.SyncBrainsKeywords(photo.ID, "scene", sidecar.Keywords); err != nil {
+		Log.Warnf("brains: failed to sync keyword index for %s: %v", photo.ID, err)
+	}
+
+	return true
+}
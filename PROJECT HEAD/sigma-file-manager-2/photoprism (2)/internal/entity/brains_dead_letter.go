@@ -0,0 +1,55 @@
+package entity
+
+import "time"
+
+// BrainsDeadLetter records a file the BRAINS pipeline gave up on after
+// exhausting its retry budget, so operators can see what failed and why
+// instead of it silently vanishing from the queue, and resubmit it once
+// the underlying cause is fixed.
+type BrainsDeadLetter struct {
+	ID        uint      `gorm:"primary_key" json:"-" yaml:"-"`
+	FilePath  string    `gorm:"type:VARBINARY(1024);index;" json:"FilePath" yaml:"FilePath"`
+	Attempts  int       `gorm:"type:INT;" json:"Attempts" yaml:"Attempts"`
+	LastError string    `gorm:"type:VARCHAR(2048);" json:"LastError" yaml:"LastError"`
+	CreatedAt time.Time `json:"CreatedAt" yaml:"-"`
+}
+
+// TableName returns the entity table name.
+func (BrainsDeadLetter) TableName() string {
+	return "brains_dead_letters"
+}
+
+// NewBrainsDeadLetter creates a dead-letter record for a file that exhausted
+// its retry budget.
+func NewBrainsDeadLetter(filePath string, attempts int, lastError string) *BrainsDeadLetter {
+	return &BrainsDeadLetter{
+		FilePath:  filePath,
+		Attempts:  attempts,
+		LastError: lastError,
+	}
+}
+
+// Save creates the dead-letter record in the database.
+func (m *BrainsDeadLetter) Save() error {
+	return Db().Create(m).Error
+}
+
+// FindBrainsDeadLetters returns the most recent dead-lettered files, newest first.
+func FindBrainsDeadLetters(limit int) ([]*BrainsDeadLetter, error) {
+	var results []*BrainsDeadLetter
+
+	if err := Db().Order("created_at desc").Limit(limit).Find(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// CountBrainsDeadLetters returns how many files are currently dead-lettered.
+func CountBrainsDeadLetters() (int64, error) {
+	var count int64
+
+	err := Db().Model(&BrainsDeadLetter{}).Count(&count).Error
+
+	return count, err
+}
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/photoprism/photoprism/pkg/rnd"
+)
+
+// BrainsFace is one face detected in a file by the offline BRAINS indexing
+// pipeline (IndexPipeline.Run), keyed by file path rather than PhotoID so it
+// can run independently of the database-backed photo index.
+type BrainsFace struct {
+	ID        string          `gorm:"type:VARBINARY(42);primary_key;" json:"ID" yaml:"-"`
+	FilePath  string          `gorm:"type:VARBINARY(1024);index;" json:"FilePath" yaml:"FilePath"`
+	X         int             `json:"X" yaml:"X"`
+	Y         int             `json:"Y" yaml:"Y"`
+	Width     int             `json:"Width" yaml:"Width"`
+	Height    int             `json:"Height" yaml:"Height"`
+	Embedding string          `gorm:"type:BLOB;" json:"-" yaml:"-"`
+	CreatedAt time.Time       `json:"CreatedAt" yaml:"-"`
+	DeletedAt *gorm.DeletedAt `gorm:"index" json:"DeletedAt,omitempty" yaml:"-"`
+}
+
+// NewBrainsFace creates a new BrainsFace record for a detected face at
+// (x, y, width, height) in filePath, with embedding already encoded as a
+// string by the caller.
+func NewBrainsFace(filePath string, x, y, width, height int, embedding string) *BrainsFace {
+	return &BrainsFace{
+		ID:        rnd.GenerateUID('c'),
+		FilePath:  filePath,
+		X:         x,
+		Y:         y,
+		Width:     width,
+		Height:    height,
+		Embedding: embedding,
+	}
+}
+
+// TableName returns the entity table name.
+func (BrainsFace) TableName() string {
+	return "brains_faces"
+}
+
+// BeforeCreate creates a random UID if needed.
+func (m *BrainsFace) BeforeCreate(scope *gorm.Scope) error {
+	if m.ID == "" {
+		m.ID = rnd.GenerateUID('c')
+		return scope.SetColumn("ID", m.ID)
+	}
+
+	return nil
+}
+
+// Save updates the record in the database or creates a new record if it
+// does not already exist.
+func (m *BrainsFace) Save() error {
+	if m.ID == "" {
+		m.ID = rnd.GenerateUID('c')
+	}
+
+	return Db().Save(m).Error
+}
@@ -0,0 +1,42 @@
+package entity
+
+import "strings"
+
+// MigrateBrainsObjectsAndKeywords backfills the brains_objects and
+// brains_keywords tables from every existing BrainsResult's ObjectResults
+// and KeywordsSorted columns, so a database populated before those tables
+// existed gets the same indexed Object/Keyword search as one processed
+// since.
+func MigrateBrainsObjectsAndKeywords() (objects int, keywords int, err error) {
+	var results []*BrainsResult
+
+	if err = Db().Find(&results).Error; err != nil {
+		return 0, 0, err
+	}
+
+	for _, r := range results {
+		if r.ObjectResults != "" {
+			if syncErr := SyncBrainsObjects(r.PhotoID, r.ObjectResults); syncErr != nil {
+				err = syncErr
+				continue
+			}
+
+			var count int64
+			Db().Model(&BrainsObject{}).Where("photo_id = ?", r.PhotoID).Count(&count)
+			objects += int(count)
+		}
+
+		if r.KeywordsSorted != "" {
+			words := strings.Split(r.KeywordsSorted, ",")
+
+			if syncErr := SyncBrainsKeywords(r.PhotoID, "scene", words); syncErr != nil {
+				err = syncErr
+				continue
+			}
+
+			keywords += len(words)
+		}
+	}
+
+	return objects, keywords, err
+}
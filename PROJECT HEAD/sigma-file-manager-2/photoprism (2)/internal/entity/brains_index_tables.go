@@ -0,0 +1,19 @@
+package entity
+
+// MigrateBrainsIndexTables creates the brains_faces, brains_labels and
+// brains_phash tables if they don't exist yet, so a fresh `photoprism
+// brains index` run can create its own schema without a separate database
+// migration step.
+func MigrateBrainsIndexTables() error {
+	return Db().AutoMigrate(&BrainsFace{}, &BrainsLabel{}, &BrainsPhash{}).Error
+}
+
+// BrainsFileIndexed reports whether filePath already has a BrainsPhash row,
+// used as the marker that a previous `photoprism brains index` run already
+// covered it, since every indexed file gets exactly one phash regardless of
+// whether any faces or labels were found in it.
+func BrainsFileIndexed(filePath string) bool {
+	var phash BrainsPhash
+
+	return Db().Where("file_path = ?", filePath).First(&phash).Error == nil
+}
@@ -0,0 +1,144 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/photoprism/photoprism/pkg/rnd"
+)
+
+// Job states for the BRAINS job queue.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobPaused    = "paused"
+	JobFailed    = "failed"
+	JobCompleted = "completed"
+)
+
+// BrainsJob represents a durable, resumable BRAINS analysis batch.
+//
+// Checkpoint is a LONGTEXT column for encoded resume state; nothing in this
+// package writes or reads it. Progress is tracked by Cursor, the index of the
+// next unprocessed entry, which Remaining resumes from; partial per-processor
+// results are not kept.
+type BrainsJob struct {
+	ID          string     `gorm:"type:VARBINARY(42);primary_key;" json:"ID" yaml:"-"`
+	Status      string     `gorm:"type:VARCHAR(16);index;default:'queued';" json:"Status" yaml:"Status"`
+	PhotoIDs    string     `gorm:"type:LONGTEXT;" json:"-" yaml:"-"`
+	Checkpoint  string     `gorm:"type:LONGTEXT;" json:"-" yaml:"-"`
+	Cursor      int        `gorm:"type:INT;default:0;" json:"Cursor" yaml:"Cursor"`
+	Total       int        `gorm:"type:INT;default:0;" json:"Total" yaml:"Total"`
+	Error       string     `gorm:"type:VARCHAR(2048);" json:"Error,omitempty" yaml:"Error,omitempty"`
+	CreatedAt   time.Time  `json:"CreatedAt" yaml:"-"`
+	UpdatedAt   time.Time  `json:"UpdatedAt" yaml:"-"`
+	CompletedAt *time.Time `json:"CompletedAt,omitempty" yaml:"-"`
+}
+
+// NewBrainsJob creates a new queued BRAINS job for the given photo IDs.
+func NewBrainsJob(photoIDs []string) *BrainsJob {
+	return &BrainsJob{
+		ID:       rnd.GenerateUID('j'),
+		Status:   JobQueued,
+		PhotoIDs: marshalStrings(photoIDs),
+		Total:    len(photoIDs),
+	}
+}
+
+// TableName returns the entity table name.
+func (BrainsJob) TableName() string {
+	return "brains_jobs"
+}
+
+// BeforeCreate creates a random UID if needed.
+func (m *BrainsJob) BeforeCreate(scope *gorm.Scope) error {
+	if m.ID == "" {
+		m.ID = rnd.GenerateUID('j')
+		return scope.SetColumn("ID", m.ID)
+	}
+
+	return nil
+}
+
+// Save updates the record in the database or creates a new record if it does not already exist.
+func (m *BrainsJob) Save() error {
+	if m.ID == "" {
+		m.ID = rnd.GenerateUID('j')
+	}
+
+	return Db().Save(m).Error
+}
+
+// Photos returns the photo IDs belonging to this job.
+func (m *BrainsJob) Photos() []string {
+	return unmarshalStrings(m.PhotoIDs)
+}
+
+// Remaining returns the photo IDs that have not been processed yet, based on the cursor.
+func (m *BrainsJob) Remaining() []string {
+	photos := m.Photos()
+
+	if m.Cursor >= len(photos) {
+		return nil
+	}
+
+	return photos[m.Cursor:]
+}
+
+// FindBrainsJob returns a BRAINS job by ID.
+func FindBrainsJob(id string) (*BrainsJob, error) {
+	result := BrainsJob{}
+
+	if err := Db().Where("id = ?", id).First(&result).Error; err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// FindBrainsJobsByStatus returns all BRAINS jobs with the given status.
+func FindBrainsJobsByStatus(status string) ([]*BrainsJob, error) {
+	var results []*BrainsJob
+
+	if err := Db().Where("status = ?", status).Find(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// marshalStrings joins a slice of strings using a separator that cannot appear in a UID.
+func marshalStrings(s []string) string {
+	result := ""
+
+	for i, v := range s {
+		if i > 0 {
+			result += "\n"
+		}
+
+		result += v
+	}
+
+	return result
+}
+
+// unmarshalStrings splits a newline-separated string back into a slice.
+func unmarshalStrings(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	var result []string
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\n' {
+			result = append(result, s[start:i])
+			start = i + 1
+		}
+	}
+
+	result = append(result, s[start:])
+
+	return result
+}
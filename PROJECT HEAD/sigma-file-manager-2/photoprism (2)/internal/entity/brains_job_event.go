@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"time"
+)
+
+// Per-file states recorded by BrainsJobEvent, mirroring the Grampus
+// job-events model: a file enters Queued when it's added to a job and
+// moves through Scheduling and Running before landing on one of the three
+// terminal states.
+const (
+	JobEventQueued     = "queued"
+	JobEventScheduling = "scheduling"
+	JobEventRunning    = "running"
+	JobEventSucceeded  = "succeeded"
+	JobEventFailed     = "failed"
+	JobEventSkipped    = "skipped"
+)
+
+// BrainsJobEvent records one state transition for one file within a
+// BrainsJob, so a UI or retry workflow can inspect exactly what happened to
+// each file in a batch instead of only seeing the job's overall outcome.
+type BrainsJobEvent struct {
+	ID        uint      `gorm:"primary_key" json:"-" yaml:"-"`
+	JobID     string    `gorm:"type:VARBINARY(42);index;" json:"JobID" yaml:"JobID"`
+	File      string    `gorm:"type:VARBINARY(1024);" json:"File" yaml:"File"`
+	State     string    `gorm:"type:VARCHAR(16);" json:"State" yaml:"State"`
+	Reason    string    `gorm:"type:VARCHAR(64);" json:"Reason,omitempty" yaml:"Reason,omitempty"`
+	Message   string    `gorm:"type:VARCHAR(2048);" json:"Message,omitempty" yaml:"Message,omitempty"`
+	CreatedAt time.Time `json:"CreatedAt" yaml:"-"`
+}
+
+// TableName returns the entity table name.
+func (BrainsJobEvent) TableName() string {
+	return "brains_job_events"
+}
+
+// NewBrainsJobEvent returns an event recording that file entered state
+// within job jobID, with an optional reason and human-readable message.
+func NewBrainsJobEvent(jobID, file, state, reason, message string) *BrainsJobEvent {
+	return &BrainsJobEvent{
+		JobID:   jobID,
+		File:    file,
+		State:   state,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// Save creates the event record. Events are never updated, only inserted.
+func (m *BrainsJobEvent) Save() error {
+	return Db().Create(m).Error
+}
+
+// FindBrainsJobEvents returns every event recorded for jobID, in
+// chronological order, with CreatedAt after since. Callers poll this with
+// the timestamp of the last event they saw to pick up only what's new, e.g.
+// a server-sent-events stream replaying a job's progress.
+func FindBrainsJobEvents(jobID string, since time.Time) ([]*BrainsJobEvent, error) {
+	var results []*BrainsJobEvent
+
+	if err := Db().
+		Where("job_id = ? AND created_at > ?", jobID, since).
+		Order("created_at ASC").
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BrainsKeyword indexes one keyword extracted from a photo's BRAINS
+// analysis, so Keyword searches can JOIN on an indexed keyword instead of
+// scanning the concatenated Keywords column with LIKE.
+type BrainsKeyword struct {
+	ID        uint      `gorm:"primary_key" json:"-" yaml:"-"`
+	PhotoID   string    `gorm:"type:VARBINARY(42);index:idx_brains_keywords_keyword_photo,priority:2;" json:"PhotoID" yaml:"-"`
+	Keyword   string    `gorm:"type:VARCHAR(64);index:idx_brains_keywords_keyword_photo,priority:1;" json:"Keyword" yaml:"Keyword"`
+	Source    string    `gorm:"type:VARCHAR(16);default:'scene';" json:"Source" yaml:"Source"`
+	CreatedAt time.Time `json:"CreatedAt" yaml:"-"`
+}
+
+// TableName returns the entity table name.
+func (BrainsKeyword) TableName() string {
+	return "brains_keywords"
+}
+
+// SyncBrainsKeywords replaces photoID's keywords previously indexed from
+// source with keywords, so Keyword searches stay in sync with each
+// re-processing run instead of drifting from the source columns.
+func SyncBrainsKeywords(photoID, source string, keywords []string) error {
+	if photoID == "" {
+		return fmt.Errorf("photo ID is missing")
+	}
+
+	if err := Db().Where("photo_id = ? AND source = ?", photoID, source).Delete(&BrainsKeyword{}).Error; err != nil {
+		return err
+	}
+
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+
+		if keyword == "" {
+			continue
+		}
+
+		row := BrainsKeyword{PhotoID: photoID, Keyword: keyword, Source: source}
+
+		if err := Db().Create(&row).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/photoprism/photoprism/pkg/rnd"
+)
+
+// BrainsLabel is one top-K label produced for a file by the offline BRAINS
+// indexing pipeline's classifier step, keyed by file path like BrainsFace
+// and BrainsPhash.
+type BrainsLabel struct {
+	ID        string          `gorm:"type:VARBINARY(42);primary_key;" json:"ID" yaml:"-"`
+	FilePath  string          `gorm:"type:VARBINARY(1024);index;" json:"FilePath" yaml:"FilePath"`
+	Label     string          `gorm:"type:VARCHAR(255);index;" json:"Label" yaml:"Label"`
+	Score     float32         `gorm:"type:FLOAT;" json:"Score" yaml:"Score"`
+	CreatedAt time.Time       `json:"CreatedAt" yaml:"-"`
+	DeletedAt *gorm.DeletedAt `gorm:"index" json:"DeletedAt,omitempty" yaml:"-"`
+}
+
+// NewBrainsLabel creates a new BrainsLabel record for filePath.
+func NewBrainsLabel(filePath, label string, score float32) *BrainsLabel {
+	return &BrainsLabel{
+		ID:       rnd.GenerateUID('c'),
+		FilePath: filePath,
+		Label:    label,
+		Score:    score,
+	}
+}
+
+// TableName returns the entity table name.
+func (BrainsLabel) TableName() string {
+	return "brains_labels"
+}
+
+// BeforeCreate creates a random UID if needed.
+func (m *BrainsLabel) BeforeCreate(scope *gorm.Scope) error {
+	if m.ID == "" {
+		m.ID = rnd.GenerateUID('c')
+		return scope.SetColumn("ID", m.ID)
+	}
+
+	return nil
+}
+
+// Save updates the record in the database or creates a new record if it
+// does not already exist.
+func (m *BrainsLabel) Save() error {
+	if m.ID == "" {
+		m.ID = rnd.GenerateUID('c')
+	}
+
+	return Db().Save(m).Error
+}
@@ -0,0 +1,122 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/photoprism/photoprism/pkg/rnd"
+)
+
+// States a BrainsModelMigration moves through: Init when the migration is
+// created, Scheduling while the new version is being downloaded and
+// verified, then one of the two terminal states.
+const (
+	MigrationInit       = "init"
+	MigrationScheduling = "scheduling"
+	MigrationSuccess    = "success"
+	MigrationFailed     = "failed"
+)
+
+// BrainsModelMigration records one attempt to swap a model type's symlink
+// to a new version fetched from the remote registry, so operators can see
+// why an update didn't apply and RollbackModel has something to swap back
+// to.
+type BrainsModelMigration struct {
+	ID             string     `gorm:"type:VARBINARY(42);primary_key;" json:"ID" yaml:"-"`
+	Name           string     `gorm:"type:VARCHAR(64);index;" json:"Name" yaml:"Name"`
+	Version        string     `gorm:"type:VARCHAR(64);" json:"Version" yaml:"Version"`
+	PreviousTarget string     `gorm:"type:VARCHAR(255);" json:"PreviousTarget,omitempty" yaml:"PreviousTarget,omitempty"`
+	NewTarget      string     `gorm:"type:VARCHAR(255);" json:"NewTarget" yaml:"NewTarget"`
+	Status         string     `gorm:"type:VARCHAR(16);default:'init';" json:"Status" yaml:"Status"`
+	FailedReason   string     `gorm:"type:VARCHAR(2048);" json:"FailedReason,omitempty" yaml:"FailedReason,omitempty"`
+	CreatedAt      time.Time  `json:"CreatedAt" yaml:"-"`
+	UpdatedAt      time.Time  `json:"UpdatedAt" yaml:"-"`
+	CompletedAt    *time.Time `json:"CompletedAt,omitempty" yaml:"-"`
+}
+
+// TableName returns the entity table name.
+func (BrainsModelMigration) TableName() string {
+	return "brains_model_migrations"
+}
+
+// NewBrainsModelMigration creates a new Init-state migration for name,
+// moving it from previousTarget to newTarget.
+func NewBrainsModelMigration(name, previousTarget, newTarget, version string) *BrainsModelMigration {
+	return &BrainsModelMigration{
+		ID:             rnd.GenerateUID('m'),
+		Name:           name,
+		Version:        version,
+		PreviousTarget: previousTarget,
+		NewTarget:      newTarget,
+		Status:         MigrationInit,
+	}
+}
+
+// BeforeCreate creates a random UID if needed.
+func (m *BrainsModelMigration) BeforeCreate(scope *gorm.Scope) error {
+	if m.ID == "" {
+		m.ID = rnd.GenerateUID('m')
+		return scope.SetColumn("ID", m.ID)
+	}
+
+	return nil
+}
+
+// Save updates the record in the database or creates a new record if it
+// does not already exist.
+func (m *BrainsModelMigration) Save() error {
+	if m.ID == "" {
+		m.ID = rnd.GenerateUID('m')
+	}
+
+	return Db().Save(m).Error
+}
+
+// Succeed marks the migration successful and stamps CompletedAt.
+func (m *BrainsModelMigration) Succeed() error {
+	now := time.Now()
+	m.Status = MigrationSuccess
+	m.CompletedAt = &now
+
+	return m.Save()
+}
+
+// Fail marks the migration failed, recording why.
+func (m *BrainsModelMigration) Fail(reason string) error {
+	now := time.Now()
+	m.Status = MigrationFailed
+	m.FailedReason = reason
+	m.CompletedAt = &now
+
+	return m.Save()
+}
+
+// LastSuccessfulMigration returns the most recently completed successful
+// migration for name, so RollbackModel knows which target to swap back to.
+func LastSuccessfulMigration(name string) (*BrainsModelMigration, error) {
+	result := BrainsModelMigration{}
+
+	if err := Db().
+		Where("name = ? AND status = ?", name, MigrationSuccess).
+		Order("completed_at DESC").
+		First(&result).Error; err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// FindBrainsModelMigrations returns every migration recorded for name, most
+// recent first.
+func FindBrainsModelMigrations(name string) ([]*BrainsModelMigration, error) {
+	var results []*BrainsModelMigration
+
+	if err := Db().
+		Where("name = ?", name).
+		Order("created_at DESC").
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
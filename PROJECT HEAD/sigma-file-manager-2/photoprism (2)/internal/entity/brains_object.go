@@ -0,0 +1,89 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BrainsObject indexes one detected object from a photo's BRAINS analysis,
+// so Object searches can JOIN on an indexed, lowercased label instead of
+// scanning the ObjectResults JSON blob with LIKE.
+type BrainsObject struct {
+	ID         uint      `gorm:"primary_key" json:"-" yaml:"-"`
+	PhotoID    string    `gorm:"type:VARBINARY(42);index:idx_brains_objects_label_photo,priority:2;" json:"PhotoID" yaml:"-"`
+	Label      string    `gorm:"type:VARCHAR(128);index:idx_brains_objects_label_photo,priority:1;" json:"Label" yaml:"Label"`
+	Confidence float32   `gorm:"type:FLOAT;" json:"Confidence" yaml:"Confidence"`
+	BBox       string    `gorm:"type:VARCHAR(64);" json:"BBox,omitempty" yaml:"BBox,omitempty"`
+	CreatedAt  time.Time `json:"CreatedAt" yaml:"-"`
+}
+
+// TableName returns the entity table name.
+func (BrainsObject) TableName() string {
+	return "brains_objects"
+}
+
+// brainsObjectJSON mirrors the json tags brains.DetectedObject encodes, so
+// this package can decode BrainsResult.ObjectResults without importing the
+// brains package, which already imports entity.
+type brainsObjectJSON struct {
+	Label      string  `json:"label"`
+	Confidence float32 `json:"confidence"`
+	X          int     `json:"x"`
+	Y          int     `json:"y"`
+	Width      int     `json:"width"`
+	Height     int     `json:"height"`
+}
+
+// SyncBrainsObjects replaces photoID's indexed objects with the contents of
+// objectsJSON (BrainsResult.ObjectResults), so Object searches stay in sync
+// with each re-processing run instead of drifting from the source JSON.
+func SyncBrainsObjects(photoID, objectsJSON string) error {
+	if photoID == "" {
+		return fmt.Errorf("photo ID is missing")
+	}
+
+	if err := Db().Where("photo_id = ?", photoID).Delete(&BrainsObject{}).Error; err != nil {
+		return err
+	}
+
+	if objectsJSON == "" {
+		return nil
+	}
+
+	var decoded []brainsObjectJSON
+
+	if err := json.Unmarshal([]byte(objectsJSON), &decoded); err != nil {
+		return fmt.Errorf("brains: failed to decode object results for %s: %v", photoID, err)
+	}
+
+	for _, o := range decoded {
+		label := strings.ToLower(strings.TrimSpace(o.Label))
+
+		if label == "" {
+			continue
+		}
+
+		row := BrainsObject{
+			PhotoID:    photoID,
+			Label:      label,
+			Confidence: o.Confidence,
+			BBox:       fmt.Sprintf("%d,%d,%d,%d", o.X, o.Y, o.Width, o.Height),
+		}
+
+		if err := Db().Create(&row).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindBrainsObjects returns every indexed object detected for photoID,
+// highest confidence first, e.g. for building a download manifest that
+// explains why a photo was selected.
+func FindBrainsObjects(photoID string) (objects []BrainsObject, err error) {
+	err = Db().Where("photo_id = ?", photoID).Order("confidence DESC").Find(&objects).Error
+	return objects, err
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/photoprism/photoprism/pkg/rnd"
+)
+
+// BrainsPhash is the perceptual hash computed for a file by the offline
+// BRAINS indexing pipeline, used for near-duplicate clustering. FilePath is
+// the primary lookup key; Hash is indexed so exact matches (the
+// common duplicate case) are a single indexed lookup rather than a
+// hamming-distance scan.
+type BrainsPhash struct {
+	ID        string    `gorm:"type:VARBINARY(42);primary_key;" json:"ID" yaml:"-"`
+	FilePath  string    `gorm:"type:VARBINARY(1024);unique_index;" json:"FilePath" yaml:"FilePath"`
+	Hash      string    `gorm:"type:VARCHAR(16);index;" json:"Hash" yaml:"Hash"`
+	CreatedAt time.Time `json:"CreatedAt" yaml:"-"`
+}
+
+// NewBrainsPhash creates a new BrainsPhash record for filePath.
+func NewBrainsPhash(filePath, hash string) *BrainsPhash {
+	return &BrainsPhash{
+		ID:       rnd.GenerateUID('c'),
+		FilePath: filePath,
+		Hash:     hash,
+	}
+}
+
+// TableName returns the entity table name.
+func (BrainsPhash) TableName() string {
+	return "brains_phash"
+}
+
+// Save updates the record in the database or creates a new record if it
+// does not already exist, keyed by FilePath so a rescan overwrites rather
+// than duplicates.
+func (m *BrainsPhash) Save() error {
+	var existing BrainsPhash
+
+	if err := Db().Where("file_path = ?", m.FilePath).First(&existing).Error; err == nil {
+		m.ID = existing.ID
+	} else if m.ID == "" {
+		m.ID = rnd.GenerateUID('c')
+	}
+
+	return Db().Save(m).Error
+}
+
+// FindSimilarPhash returns every BrainsPhash row sharing hash with a file
+// other than filePath, i.e. its exact near-duplicate candidates.
+func FindSimilarPhash(filePath, hash string) ([]BrainsPhash, error) {
+	var matches []BrainsPhash
+
+	err := Db().Where("hash = ? AND file_path <> ?", hash, filePath).Find(&matches).Error
+
+	return matches, err
+}
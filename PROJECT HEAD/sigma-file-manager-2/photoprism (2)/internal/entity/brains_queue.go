@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Queue status values for BrainsQueue.
+const (
+	QueuePending = "pending"
+	QueueLeased  = "leased"
+)
+
+// BrainsQueue is one file waiting to be (re-)processed by the BRAINS
+// pipeline. Persisting the queue lets ProcessFiles enqueue work and return
+// immediately, and means a crash loses at most the row a worker had leased
+// rather than the whole backlog.
+type BrainsQueue struct {
+	ID            uint         `gorm:"primary_key" json:"-" yaml:"-"`
+	FilePath      string       `gorm:"type:VARBINARY(1024);index;" json:"FilePath" yaml:"FilePath"`
+	Status        string       `gorm:"type:VARCHAR(16);index;default:'pending';" json:"Status" yaml:"Status"`
+	Attempts      int          `gorm:"type:INT;default:0;" json:"Attempts" yaml:"Attempts"`
+	NextAttemptAt time.Time    `gorm:"index;" json:"NextAttemptAt" yaml:"NextAttemptAt"`
+	LeasedAt      sql.NullTime `json:"LeasedAt,omitempty" yaml:"-"`
+	LastError     string       `gorm:"type:VARCHAR(2048);" json:"LastError,omitempty" yaml:"LastError,omitempty"`
+	CreatedAt     time.Time    `json:"CreatedAt" yaml:"-"`
+	UpdatedAt     time.Time    `json:"UpdatedAt" yaml:"-"`
+}
+
+// TableName returns the entity table name.
+func (BrainsQueue) TableName() string {
+	return "brains_queue"
+}
+
+// NewBrainsQueueEntry creates a new, immediately-eligible queue row for filePath.
+func NewBrainsQueueEntry(filePath string) *BrainsQueue {
+	return &BrainsQueue{
+		FilePath:      filePath,
+		Status:        QueuePending,
+		NextAttemptAt: time.Now(),
+	}
+}
+
+// Save updates the record in the database or creates a new record if it does not already exist.
+func (m *BrainsQueue) Save() error {
+	return Db().Save(m).Error
+}
+
+// Delete removes the row, e.g. once its file has finished processing or has
+// been handed off to the dead-letter table.
+func (m *BrainsQueue) Delete() error {
+	return Db().Delete(m).Error
+}
+
+// LeaseNextBrainsQueueEntry claims the oldest eligible pending row for
+// processing inside its own transaction, so concurrent workers (including
+// on other hosts sharing the database) never lease the same row twice. This
+// stands in for `SELECT ... FOR UPDATE SKIP LOCKED`, which SQLite doesn't
+// support: the transaction's write lock serializes the claim instead.
+func LeaseNextBrainsQueueEntry() (*BrainsQueue, error) {
+	tx := Db().Begin()
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	var entry BrainsQueue
+
+	err := tx.
+		Where("status = ? AND next_attempt_at <= ?", QueuePending, time.Now()).
+		Order("next_attempt_at, id").
+		First(&entry).Error
+
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	entry.Status = QueueLeased
+	entry.LeasedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	if err := tx.Save(&entry).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return &entry, tx.Commit().Error
+}
+
+// CountBrainsQueueByStatus returns how many queue rows currently have the given status.
+func CountBrainsQueueByStatus(status string) (int64, error) {
+	var count int64
+
+	err := Db().Model(&BrainsQueue{}).Where("status = ?", status).Count(&count).Error
+
+	return count, err
+}
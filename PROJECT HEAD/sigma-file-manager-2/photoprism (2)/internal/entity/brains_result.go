@@ -70,6 +70,19 @@ func FindBrainsResult(photoID string) (*BrainsResult, error) {
 	return &result, nil
 }
 
+// FindBrainsResultsWithEmbedding returns every BrainsResult that has a
+// non-empty Embedding column, for building or rebuilding the in-memory
+// vector search index.
+func FindBrainsResultsWithEmbedding() ([]*BrainsResult, error) {
+	var results []*BrainsResult
+
+	if err := Db().Where("embedding != ''").Find(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
 // GetOrCreateBrainsResult returns existing brains results or creates new ones.
 func GetOrCreateBrainsResult(photoID string) (*BrainsResult, error) {
 	if photoID == "" {
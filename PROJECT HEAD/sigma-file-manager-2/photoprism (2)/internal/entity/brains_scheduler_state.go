@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// schedulerStateID is the fixed primary key of the single watermark row
+// BrainsSchedulerState keeps, since the scheduler only ever tracks one set
+// of "have things changed since last run" markers.
+const schedulerStateID = "default"
+
+// BrainsSchedulerState records the watermarks the BRAINS scheduler compared
+// against on its last completed run, so a process restart can tell whether
+// anything changed while it was down instead of forcing a full rescan.
+type BrainsSchedulerState struct {
+	ID                 string    `gorm:"type:VARBINARY(42);primary_key;" json:"ID" yaml:"-"`
+	LastPhotoUpdatedAt time.Time `json:"LastPhotoUpdatedAt" yaml:"-"`
+	ModelFingerprint   string    `gorm:"type:VARCHAR(255);" json:"ModelFingerprint" yaml:"-"`
+	LastRunAt          time.Time `json:"LastRunAt" yaml:"-"`
+	UpdatedAt          time.Time `json:"UpdatedAt" yaml:"-"`
+}
+
+// TableName returns the entity table name.
+func (BrainsSchedulerState) TableName() string {
+	return "brains_scheduler_state"
+}
+
+// BeforeCreate pins the fixed singleton ID if needed.
+func (m *BrainsSchedulerState) BeforeCreate(scope *gorm.Scope) error {
+	if m.ID == "" {
+		m.ID = schedulerStateID
+		return scope.SetColumn("ID", m.ID)
+	}
+
+	return nil
+}
+
+// LoadBrainsSchedulerState returns the scheduler's watermark row, creating
+// an empty one on first run so every field starts at its zero value.
+func LoadBrainsSchedulerState() (*BrainsSchedulerState, error) {
+	result := BrainsSchedulerState{}
+
+	if err := Db().Where("id = ?", schedulerStateID).First(&result).Error; err == nil {
+		return &result, nil
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	result = BrainsSchedulerState{ID: schedulerStateID}
+
+	if err := result.Save(); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Save updates the watermark row in the database or creates it if it does
+// not already exist.
+func (m *BrainsSchedulerState) Save() error {
+	if m.ID == "" {
+		m.ID = schedulerStateID
+	}
+
+	return Db().Save(m).Error
+}
@@ -0,0 +1,41 @@
+package entity
+
+import "time"
+
+// ConvertState records that a file has already been processed by a
+// `photoprism convert` or `photoprism thumbs` run, keyed by its absolute
+// path and the command that processed it, so a `--resume` run can skip it
+// without redoing a deterministic, sorted-order directory walk from
+// scratch.
+type ConvertState struct {
+	FilePath  string    `gorm:"type:VARBINARY(1024);primary_key;" json:"FilePath"`
+	Command   string    `gorm:"type:VARCHAR(16);primary_key;" json:"Command"`
+	UpdatedAt time.Time `json:"UpdatedAt"`
+}
+
+// TableName returns the entity table name.
+func (ConvertState) TableName() string {
+	return "convert_state"
+}
+
+// MigrateConvertState creates the convert_state table if it doesn't exist
+// yet.
+func MigrateConvertState() error {
+	return Db().AutoMigrate(&ConvertState{}).Error
+}
+
+// MarkConvertDone records filePath as completed for command ("convert" or
+// "thumbs").
+func MarkConvertDone(command, filePath string) error {
+	state := ConvertState{FilePath: filePath, Command: command}
+
+	return Db().Save(&state).Error
+}
+
+// ConvertDone reports whether filePath has already been recorded as
+// completed for command.
+func ConvertDone(command, filePath string) bool {
+	var state ConvertState
+
+	return Db().Where("file_path = ? AND command = ?", filePath, command).First(&state).Error == nil
+}
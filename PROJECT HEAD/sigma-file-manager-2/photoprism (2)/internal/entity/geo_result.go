@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// GeoResult stores one row returned by a geo-aware search, carrying just
+// enough data for the map view to place and cluster a marker without a
+// second query per photo.
+type GeoResult struct {
+	PhotoUID   string    `json:"PhotoUID"`
+	PhotoLat   float32   `json:"PhotoLat"`
+	PhotoLng   float32   `json:"PhotoLng"`
+	PhotoTitle string    `json:"PhotoTitle"`
+	TakenAt    time.Time `json:"TakenAt"`
+	CellID     string    `json:"CellID"`
+}
+
+// GeoResults is a list of rows returned by a geo-aware search.
+type GeoResults []GeoResult
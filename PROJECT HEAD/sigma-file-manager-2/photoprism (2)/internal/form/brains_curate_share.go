@@ -0,0 +1,12 @@
+package form
+
+// BrainsCurateShare is the optional "share" object in a
+// CurateBrainsCollections request body, letting an admin publish every
+// album CurateBrainsCollections produces as a constrained entity.Link in
+// the same call, instead of hand-managing a share per album afterwards.
+type BrainsCurateShare struct {
+	Expires    int    `json:"expires"`     // Link lifetime in seconds, 0 for no expiration
+	MaxViews   int    `json:"max_views"`   // Maximum number of views, 0 for unlimited
+	Password   string `json:"password"`    // Optional password required to open the link
+	SlugPrefix string `json:"slug_prefix"` // Prefix for the generated share slug, e.g. "best-of-2024"
+}
@@ -0,0 +1,35 @@
+package form
+
+// SearchBrains defines all parameters accepted by BrainsSearch.Find. It
+// mirrors SearchPhotos' Query/Count/Offset/Order pagination fields so BRAINS
+// results page the same way photo search results do, with one field per
+// attribute BrainsSearch used to expose as its own method, so a caller can
+// combine any number of them ("outdoor" + "golden-hour" + "aesthetic>7")
+// into a single query instead of intersecting several result sets by hand.
+type SearchBrains struct {
+	Query  string `form:"q"`
+	Count  int    `form:"count"`
+	Offset int    `form:"offset"`
+	Order  string `form:"order"`
+
+	AestheticMin        float32 `form:"aesthetic_min"`
+	AestheticMax        float32 `form:"aesthetic_max"`
+	SceneType           string  `form:"scene_type"`
+	IndoorOutdoor       string  `form:"indoor_outdoor"`
+	TimeOfDay           string  `form:"time_of_day"`
+	Weather             string  `form:"weather"`
+	Keyword             string  `form:"keyword"`
+	Object              string  `form:"object"`
+	ObjectMinConfidence float32 `form:"object_min_confidence"`
+	Emotion             string  `form:"emotion"`
+	EmotionMin          float32 `form:"emotion_min"`
+
+	// Objects, Keywords and Emotions are the comma-separated, multi-value
+	// forms of Object/Keyword/Emotion used by SearchBrainsPhotos, so a
+	// client can ask for "cat:0.8,dog:0.5" or "sunset,mountains" without
+	// intersecting several single-attribute requests by hand. A photo
+	// matches if it satisfies any one entry.
+	Objects  string `form:"objects"`
+	Keywords string `form:"keywords"`
+	Emotions string `form:"emotions"`
+}
@@ -0,0 +1,27 @@
+package form
+
+// SearchBrainsGeo defines the parameters accepted by BrainsSearch.BrainsGeo:
+// a map viewport, an S2 cell, or a photo to search around, combined with
+// any number of BRAINS predicates.
+type SearchBrainsGeo struct {
+	// S2 is an S2 cell token, e.g. "89c25c", matched by prefix against
+	// photos.cell_id, so a shorter token covers a larger area. Takes
+	// precedence over Near and the bounding box fields.
+	S2 string `form:"s2"`
+
+	// Near searches around the coordinates of an existing photo instead of
+	// an explicit viewport or S2 cell.
+	Near string `form:"near"`
+
+	// North, South, East and West describe a map viewport in decimal
+	// degrees; all four must be set for the bounding box to apply.
+	North float32 `form:"north"`
+	South float32 `form:"south"`
+	East  float32 `form:"east"`
+	West  float32 `form:"west"`
+
+	SceneType    string  `form:"scene_type"`
+	Weather      string  `form:"weather"`
+	Object       string  `form:"object"`
+	AestheticMin float32 `form:"aesthetic_min"`
+}
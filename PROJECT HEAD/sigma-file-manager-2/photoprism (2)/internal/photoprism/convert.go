@@ -2,9 +2,12 @@ package photoprism
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
+	"encoding/xml"
 	"fmt"
+	"image"
+	"image/jpeg"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -12,12 +15,12 @@ import (
 	"runtime"
 	"runtime/debug"
 	"sync"
+	"time"
 
-	"github.com/karrick/godirwalk"
+	"golang.org/x/image/webp"
 
 	"github.com/photoprism/photoprism/internal/config"
 	"github.com/photoprism/photoprism/internal/mutex"
-	"github.com/photoprism/photoprism/pkg/clean"
 	"github.com/photoprism/photoprism/pkg/fs"
 	"github.com/photoprism/photoprism/pkg/list"
 	"github.com/photoprism/photoprism/pkg/txt"
@@ -26,10 +29,35 @@ import (
 // SupportedFormats is a list of supported image and video formats
 var SupportedFormats = []string{
 	".jpg", ".jpeg", ".png", ".gif", ".tiff", ".bmp", ".heic", ".heif",
+	".webp", ".avif",
 	".mp4", ".mov", ".avi", ".webm", ".mkv",
 	".cr2", ".nef", ".arw", ".dng", ".orf", ".rw2", ".pef", ".srw",
 }
 
+// convertTimeout bounds how long a single RawConverter.Convert call may run
+// before ConvertToJpeg gives up on it.
+const convertTimeout = 10 * time.Minute
+
+// Output formats selectable via ConvertCommand's --format flag.
+const (
+	FormatJpeg = "jpeg"
+	FormatWebp = "webp"
+	FormatAvif = "avif"
+)
+
+// ResolutionLimitError is returned when a file exceeds the configured
+// --resolution-limit, so callers can skip it rather than treat it as a
+// generic conversion failure.
+type ResolutionLimitError struct {
+	Megapixels float64
+	Limit      float64
+}
+
+// Error implements the error interface.
+func (e *ResolutionLimitError) Error() string {
+	return fmt.Sprintf("convert: %.1f MP exceeds the %.1f MP resolution limit", e.Megapixels, e.Limit)
+}
+
 // CalculateOptimalWorkers determines the optimal number of workers based on system resources
 func CalculateOptimalWorkers(maxWorkers int) int {
 	cpuCores := runtime.NumCPU()
@@ -54,6 +82,8 @@ type Convert struct {
 	rawTherapeeExclude fs.ExtList
 	imageMagickExclude fs.ExtList
 	supportedFormats   fs.ExtList
+	jsonCache          *SidecarCache
+	converters         *ConverterRegistry
 }
 
 // NewConvert returns a new file format conversion worker.
@@ -65,6 +95,8 @@ func NewConvert(conf *config.Config) *Convert {
 		rawTherapeeExclude: fs.NewExtList(conf.RawTherapeeExclude()),
 		imageMagickExclude: fs.NewExtList(conf.ImageMagickExclude()),
 		supportedFormats:   fs.NewExtList(SupportedFormats),
+		jsonCache:          NewSidecarCache(conf),
+		converters:         NewConverterRegistry(conf),
 	}
 
 	return c
@@ -76,10 +108,11 @@ func (c *Convert) IsSupportedFormat(filename string) bool {
 	return c.supportedFormats.Contains(ext)
 }
 
-// handleRawFile processes RAW files using the appropriate converter
-func (c *Convert) handleRawFile(f *MediaFile) error {
+// handleRawFile processes RAW, WebP and AVIF files using the appropriate
+// converter for the requested output format.
+func (c *Convert) handleRawFile(f *MediaFile, format string) error {
 	if c.conf.RawPresets() {
-		if err := c.ConvertToJpeg(f); err != nil {
+		if err := c.ConvertToFormat(f, format); err != nil {
 			log.Errorf("convert: %s", err)
 			return err
 		}
@@ -91,10 +124,48 @@ func (c *Convert) handleRawFile(f *MediaFile) error {
 		}
 	}
 
+	if c.conf.SidecarXmp() {
+		if xmpErr := c.CreateSidecarXmp(f); xmpErr != nil {
+			log.Errorf("convert: %s", xmpErr)
+		}
+	}
+
+	return nil
+}
+
+// ConvertToFormat converts f to the requested output format, defaulting to
+// JPEG when format is empty or unrecognized.
+func (c *Convert) ConvertToFormat(f *MediaFile, format string) error {
+	switch format {
+	case FormatWebp:
+		return c.ConvertToWebp(f)
+	case FormatAvif:
+		return c.ConvertToAvif(f)
+	default:
+		return c.ConvertToJpeg(f)
+	}
+}
+
+// checkResolutionLimit rejects f with a *ResolutionLimitError if it exceeds
+// conf.ResolutionLimit(), so oversize inputs are caught before spawning
+// darktable/rawtherapee/cwebp/avifenc rather than letting them OOM on it.
+func (c *Convert) checkResolutionLimit(f *MediaFile) error {
+	limit := c.conf.ResolutionLimit()
+
+	if limit <= 0 {
+		return nil
+	}
+
+	megapixels := float64(f.Width()*f.Height()) / 1_000_000
+
+	if megapixels > limit {
+		return &ResolutionLimitError{Megapixels: megapixels, Limit: limit}
+	}
+
 	return nil
 }
 
-// ConvertToJpeg converts a RAW file to JPEG format
+// ConvertToJpeg converts a RAW, WebP or AVIF file to JPEG format.
 func (c *Convert) ConvertToJpeg(f *MediaFile) error {
 	if f.IsJpeg() {
 		return nil
@@ -106,30 +177,192 @@ func (c *Convert) ConvertToJpeg(f *MediaFile) error {
 		return nil // JPEG already exists
 	}
 
-	// Use appropriate RAW converter based on configuration
-	var cmd *exec.Cmd
-	if c.conf.UseDarktable() {
-		cmd = exec.Command("darktable-cli", f.AbsPath, jpegFilename)
-	} else if c.conf.UseRawTherapee() {
-		cmd = exec.Command("rawtherapee-cli", "-o", jpegFilename, "-c", f.AbsPath)
-	} else {
+	if err := c.checkResolutionLimit(f); err != nil {
+		return err
+	}
+
+	if f.IsWebp() {
+		return c.decodeWebpToJpeg(f, jpegFilename)
+	}
+
+	if f.IsAvif() {
+		return c.decodeAvifToJpeg(f, jpegFilename)
+	}
+
+	converter := c.converters.Select(f)
+
+	if converter == nil {
 		return fmt.Errorf("no suitable RAW converter configured")
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), convertTimeout)
+	defer cancel()
+
+	opts := RawConvertOptions{XmpPath: xmpSidecar(f)}
+
+	if err := converter.Convert(ctx, f, jpegFilename, opts); err != nil {
+		return fmt.Errorf("error converting RAW to JPEG with %s: %v", converter.Name(), err)
+	}
+
+	if _, err := os.Stat(jpegFilename); err != nil {
+		return fmt.Errorf("%s reported success but did not create %s", converter.Name(), jpegFilename)
+	}
+
+	return nil
+}
+
+// xmpSidecar returns f's XMP sidecar path if one exists and is newer than
+// f itself, so ConvertToJpeg can tell a converter like darktable to bake in
+// whatever crops or curves it contains; it returns "" if there's no
+// sidecar yet, or f has been re-exported since the sidecar was last
+// written, since a stale sidecar's edits would no longer apply.
+func xmpSidecar(f *MediaFile) string {
+	xmpFilename := f.AbsPath + ".xmp"
+
+	xmpInfo, err := os.Stat(xmpFilename)
+	if err != nil {
+		return ""
+	}
+
+	srcInfo, err := os.Stat(f.AbsPath)
+	if err != nil || !xmpInfo.ModTime().After(srcInfo.ModTime()) {
+		return ""
+	}
+
+	return xmpFilename
+}
+
+// decodeWebpToJpeg decodes a WebP file natively using golang.org/x/image/webp
+// and re-encodes it as JPEG, avoiding a shell-out for the common case.
+func (c *Convert) decodeWebpToJpeg(f *MediaFile, jpegFilename string) error {
+	src, err := os.Open(f.AbsPath)
+	if err != nil {
+		return fmt.Errorf("error opening WebP file: %v", err)
+	}
+	defer src.Close()
+
+	img, err := webp.Decode(src)
+	if err != nil {
+		return fmt.Errorf("error decoding WebP file: %v", err)
+	}
+
+	return encodeJpeg(img, jpegFilename)
+}
+
+// decodeAvifToJpeg decodes an AVIF file by shelling out to libavif's
+// avifdec, since no pure Go AVIF decoder is vendored here.
+func (c *Convert) decodeAvifToJpeg(f *MediaFile, jpegFilename string) error {
+	cmd := exec.Command("avifdec", f.AbsPath, jpegFilename)
+
 	var out bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error converting AVIF to JPEG: %v\nStderr: %s", err, stderr.String())
+	}
+
+	return nil
+}
+
+// encodeJpeg writes img to fileName as a JPEG file.
+func encodeJpeg(img image.Image, fileName string) error {
+	out, err := os.Create(fileName)
 	if err != nil {
-		return fmt.Errorf("error converting RAW to JPEG: %v\nStderr: %s", err, stderr.String())
+		return fmt.Errorf("error creating JPEG file: %v", err)
+	}
+	defer out.Close()
+
+	return jpeg.Encode(out, img, &jpeg.Options{Quality: 92})
+}
+
+// rasterSourcePath returns the path to a file cwebp/avifenc can decode
+// directly: f.AbsPath for anything already in a raster format, or the
+// JPEG ConvertToJpeg produces for a camera RAW original, since neither
+// encoder can read RAW sensor data.
+func (c *Convert) rasterSourcePath(f *MediaFile) (string, error) {
+	if !f.IsRaw() {
+		return f.AbsPath, nil
+	}
+
+	if err := c.ConvertToJpeg(f); err != nil {
+		return "", fmt.Errorf("error decoding RAW before encoding: %v", err)
+	}
+
+	return f.AbsPath + ".jpg", nil
+}
+
+// ConvertToWebp encodes f as a WebP file using cwebp. A camera RAW source
+// is decoded to JPEG first via rasterSourcePath, since cwebp can't read
+// RAW sensor data.
+func (c *Convert) ConvertToWebp(f *MediaFile) error {
+	if err := c.checkResolutionLimit(f); err != nil {
+		return err
+	}
+
+	webpFilename := f.AbsPath + ".webp"
+
+	if _, err := os.Stat(webpFilename); err == nil {
+		return nil // WebP already exists
+	}
+
+	sourcePath, err := c.rasterSourcePath(f)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("cwebp", sourcePath, "-o", webpFilename)
+
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error converting to WebP: %v\nStderr: %s", err, stderr.String())
 	}
 
 	return nil
 }
 
-// CreateSidecarJson creates a JSON sidecar file for the given media file
+// ConvertToAvif encodes f as an AVIF file using libavif's avifenc. A
+// camera RAW source is decoded to JPEG first via rasterSourcePath, since
+// avifenc can't read RAW sensor data.
+func (c *Convert) ConvertToAvif(f *MediaFile) error {
+	if err := c.checkResolutionLimit(f); err != nil {
+		return err
+	}
+
+	avifFilename := f.AbsPath + ".avif"
+
+	if _, err := os.Stat(avifFilename); err == nil {
+		return nil // AVIF already exists
+	}
+
+	sourcePath, err := c.rasterSourcePath(f)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("avifenc", sourcePath, avifFilename)
+
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error converting to AVIF: %v\nStderr: %s", err, stderr.String())
+	}
+
+	return nil
+}
+
+// CreateSidecarJson creates a JSON sidecar file for the given media file. The
+// result is cached by the hash of f's bytes, not its path, so a renamed or
+// moved original that's already been scanned doesn't cost another ExifTool
+// spawn on the next index/import run.
 func (c *Convert) CreateSidecarJson(f *MediaFile) error {
 	jsonFilename := f.AbsPath + ".json"
 
@@ -137,6 +370,20 @@ func (c *Convert) CreateSidecarJson(f *MediaFile) error {
 		return nil // JSON sidecar already exists
 	}
 
+	hash, hashErr := hashFile(f.AbsPath)
+
+	if hashErr == nil {
+		if cached, ok := c.jsonCache.Get(hash); ok {
+			if err := ioutil.WriteFile(jsonFilename, cached, 0644); err != nil {
+				return fmt.Errorf("error writing cached JSON sidecar file: %v", err)
+			}
+
+			return nil
+		}
+	} else {
+		log.Warnf("convert: %s", hashErr)
+	}
+
 	metadata, err := f.Metadata()
 	if err != nil {
 		return fmt.Errorf("error extracting metadata: %v", err)
@@ -152,11 +399,121 @@ func (c *Convert) CreateSidecarJson(f *MediaFile) error {
 		return fmt.Errorf("error writing JSON sidecar file: %v", err)
 	}
 
+	if hashErr == nil {
+		if err := c.jsonCache.Put(hash, jsonData); err != nil {
+			log.Warnf("convert: failed to cache JSON sidecar: %v", err)
+		}
+	}
+
 	return nil
 }
 
-// Start converts all files in the specified directory based on the current configuration.
-func (w *Convert) Start(dir string, ext []string, force bool) (err error) {
+// CreateSidecarXmp writes an XMP sidecar for f, deriving dc:title,
+// dc:subject, xmp:Rating, GPS and the capture date from the same ExifTool
+// metadata CreateSidecarJson uses, so an XMP-aware tool like darktable can
+// read back title, keywords and rating set elsewhere. Unlike the JSON
+// sidecar, an XMP sidecar is expected to be rewritten afterwards (darktable
+// writes crops and curves back into it), so an existing file is never
+// overwritten here.
+func (c *Convert) CreateSidecarXmp(f *MediaFile) error {
+	xmpFilename := f.AbsPath + ".xmp"
+
+	if _, err := os.Stat(xmpFilename); err == nil {
+		return nil // XMP sidecar already exists
+	}
+
+	metadata, err := f.Metadata()
+	if err != nil {
+		return fmt.Errorf("error extracting metadata: %v", err)
+	}
+
+	data := xmpPacket(metadata.Title, metadata.Keywords, metadata.Rating, metadata.Lat, metadata.Lng, metadata.TakenAt)
+
+	if err := ioutil.WriteFile(xmpFilename, data, 0644); err != nil {
+		return fmt.Errorf("error writing XMP sidecar file: %v", err)
+	}
+
+	return nil
+}
+
+// xmpPacket renders title, keywords, rating, GPS coordinates and the
+// capture date as a standalone XMP packet, omitting any element whose
+// value is empty or zero rather than writing it out blank.
+func xmpPacket(title string, keywords []string, rating int, lat, lng float32, takenAt time.Time) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("<?xpacket begin=\"\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	buf.WriteString("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">\n")
+	buf.WriteString(" <rdf:RDF xmlns:rdf=\"http://www.w3.org/1999/02/22-rdf-syntax-ns#\">\n")
+	buf.WriteString("  <rdf:Description rdf:about=\"\"\n")
+	buf.WriteString("    xmlns:dc=\"http://purl.org/dc/elements/1.1/\"\n")
+	buf.WriteString("    xmlns:xmp=\"http://ns.adobe.com/xap/1.0/\"\n")
+	buf.WriteString("    xmlns:exif=\"http://ns.adobe.com/exif/1.0/\">\n")
+
+	if title != "" {
+		buf.WriteString("   <dc:title><rdf:Alt><rdf:li xml:lang=\"x-default\">")
+		xml.EscapeText(&buf, []byte(title))
+		buf.WriteString("</rdf:li></rdf:Alt></dc:title>\n")
+	}
+
+	if len(keywords) > 0 {
+		buf.WriteString("   <dc:subject><rdf:Bag>\n")
+		for _, keyword := range keywords {
+			buf.WriteString("    <rdf:li>")
+			xml.EscapeText(&buf, []byte(keyword))
+			buf.WriteString("</rdf:li>\n")
+		}
+		buf.WriteString("   </rdf:Bag></dc:subject>\n")
+	}
+
+	if rating != 0 {
+		fmt.Fprintf(&buf, "   <xmp:Rating>%d</xmp:Rating>\n", rating)
+	}
+
+	if lat != 0 || lng != 0 {
+		fmt.Fprintf(&buf, "   <exif:GPSLatitude>%s</exif:GPSLatitude>\n", gpsCoord(lat, 'N', 'S'))
+		fmt.Fprintf(&buf, "   <exif:GPSLongitude>%s</exif:GPSLongitude>\n", gpsCoord(lng, 'E', 'W'))
+	}
+
+	if !takenAt.IsZero() {
+		fmt.Fprintf(&buf, "   <xmp:CreateDate>%s</xmp:CreateDate>\n", takenAt.UTC().Format(time.RFC3339))
+	}
+
+	buf.WriteString("  </rdf:Description>\n")
+	buf.WriteString(" </rdf:RDF>\n")
+	buf.WriteString("</x:xmpmeta>\n")
+	buf.WriteString("<?xpacket end=\"w\"?>\n")
+
+	return buf.Bytes()
+}
+
+// gpsCoord formats a decimal-degree coordinate in XMP's "degrees,minutes"
+// form (DDD,MM.mmmmmmR), picking pos or neg as the hemisphere letter
+// depending on deg's sign.
+func gpsCoord(deg float32, pos, neg byte) string {
+	hemisphere := pos
+	if deg < 0 {
+		hemisphere = neg
+		deg = -deg
+	}
+
+	degrees := int(deg)
+	minutes := (float64(deg) - float64(degrees)) * 60
+
+	return fmt.Sprintf("%d,%.6f%c", degrees, minutes, hemisphere)
+}
+
+// convertStateCommand is the entity.ConvertState command key Convert.Start
+// records completed files under, distinguishing them from ones completed
+// by Thumbs.Start.
+const convertStateCommand = "convert"
+
+// Start converts all files in the specified directory based on the current
+// configuration. Files are dispatched in stable, lexically sorted path
+// order rather than filesystem walk order, so opt.StartPath/opt.Limit
+// produce reproducible ranges and opt.Resume can skip files a previous,
+// interrupted run already finished.
+func (w *Convert) Start(dir string, ext []string, opt ConvertOptions) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("convert: %s (panic)\nstack: %s", r, debug.Stack())
@@ -170,6 +527,15 @@ func (w *Convert) Start(dir string, ext []string, force bool) (err error) {
 
 	defer mutex.IndexWorker.Stop()
 
+	files, err := walkSortedFiles(dir, w.supportedFormats)
+	if err != nil {
+		log.Infof("convert: %s", err)
+	}
+
+	files = applyConvertOptions(files, convertStateCommand, opt)
+
+	log.Infof("convert: processing %d files in sorted order", len(files))
+
 	jobs := make(chan ConvertJob)
 
 	// Start an optimal number of goroutines to convert files.
@@ -184,75 +550,49 @@ func (w *Convert) Start(dir string, ext []string, force bool) (err error) {
 		}()
 	}
 
-	done := make(fs.Done)
-	ignore := fs.NewIgnoreList(fs.PPIgnoreFilename, true, false)
-
-	if err = ignore.Path(dir); err != nil {
-		log.Infof("convert: %s", err)
-	}
+	for _, fileName := range files {
+		if mutex.IndexWorker.Canceled() {
+			break
+		}
 
-	ignore.Log = func(fileName string) {
-		log.Infof("convert: ignoring %s", clean.Log(filepath.Base(fileName)))
+		w.processFile(fileName, opt, jobs)
 	}
 
-	err = godirwalk.Walk(dir, &godirwalk.Options{
-		ErrorCallback: func(fileName string, err error) godirwalk.ErrorAction {
-			return godirwalk.SkipNode
-		},
-		Callback: func(fileName string, info *godirwalk.Dirent) error {
-			defer func() {
-				if r := recover(); r != nil {
-					log.Errorf("convert: %s (panic)\nstack: %s", r, debug.Stack())
-				}
-			}()
-
-			if mutex.IndexWorker.Canceled() {
-				return errors.New("canceled")
-			}
-
-			isDir, _ := info.IsDirOrSymlinkToDir()
-			isSymlink := info.IsSymlink()
-
-			// Skip file?
-			if skip, result := fs.SkipWalk(fileName, isDir, isSymlink, done, ignore); skip {
-				return result
-			}
-
-			// Process only supported file formats
-			if !w.IsSupportedFormat(fileName) {
-				return nil
-			}
-
-			f, err := NewMediaFile(fileName)
+	close(jobs)
+	wg.Wait()
 
-			if err != nil || f.Empty() || f.IsPreviewImage() || !f.IsMedia() {
-				return nil
-			}
+	return nil
+}
 
-			// Improved RAW file handling
-			if f.IsRaw() {
-				if err := w.handleRawFile(f); err != nil {
-					log.Errorf("convert: error handling RAW file %s: %v", fileName, err)
-					return nil
-				}
-			}
+// processFile queues a single file for conversion, recovering from panics
+// the way the previous walk-driven callback did so one bad file can't abort
+// the whole sorted-order run.
+func (w *Convert) processFile(fileName string, opt ConvertOptions, jobs chan<- ConvertJob) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("convert: %s (panic)\nstack: %s", r, debug.Stack())
+		}
+	}()
 
-			done[fileName] = fs.Processed
+	f, err := NewMediaFile(fileName)
 
-			jobs <- ConvertJob{
-				force:   force,
-				file:    f,
-				convert: w,
-			}
+	if err != nil || f.Empty() || f.IsPreviewImage() || !f.IsMedia() {
+		return
+	}
 
-			return nil
-		},
-		Unsorted:            false,
-		FollowSymbolicLinks: true,
-	})
+	// RAW, WebP and AVIF files need a dedicated decode/encode step.
+	if f.IsRaw() || f.IsWebp() || f.IsAvif() {
+		if err := w.handleRawFile(f, opt.Format); err != nil {
+			log.Errorf("convert: error handling %s: %v", fileName, err)
+			return
+		}
+	}
 
-	close(jobs)
-	wg.Wait()
+	jobs <- ConvertJob{
+		force:   opt.Force,
+		file:    f,
+		convert: w,
+	}
 
-	return err
+	recordConvertDone(convertStateCommand, fileName)
 }
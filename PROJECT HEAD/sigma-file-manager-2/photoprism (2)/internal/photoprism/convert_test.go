@@ -1,9 +1,11 @@
 package photoprism
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/photoprism/photoprism/internal/config"
 	"github.com/stretchr/testify/assert"
@@ -25,9 +27,64 @@ func TestConvert_IsSupportedFormat(t *testing.T) {
 	assert.True(t, convert.IsSupportedFormat("test.jpg"))
 	assert.True(t, convert.IsSupportedFormat("test.JPG"))
 	assert.True(t, convert.IsSupportedFormat("test.raw"))
+	assert.True(t, convert.IsSupportedFormat("test.webp"))
+	assert.True(t, convert.IsSupportedFormat("test.WEBP"))
+	assert.True(t, convert.IsSupportedFormat("test.avif"))
 	assert.False(t, convert.IsSupportedFormat("test.txt"))
 }
 
+func TestConvert_ConvertToFormat(t *testing.T) {
+	c := config.TestConfig()
+	convert := NewConvert(c)
+
+	tempDir, _ := os.MkdirTemp("", "photoprism_test")
+	defer os.RemoveAll(tempDir)
+
+	rawFilePath := filepath.Join(tempDir, "test.raw")
+	os.WriteFile(rawFilePath, []byte("dummy raw data"), 0644)
+
+	mediaFile, err := NewMediaFile(rawFilePath)
+	assert.NoError(t, err)
+
+	// FormatWebp falls through to ConvertToWebp, which must decode a RAW
+	// source to JPEG first; with no RAW converter registered/installed,
+	// that decode step fails before cwebp is ever invoked.
+	err = convert.ConvertToFormat(mediaFile, FormatWebp)
+	assert.Error(t, err)
+}
+
+func TestConvert_ConvertToWebp_DecodesRawSourceFirst(t *testing.T) {
+	c := config.TestConfig()
+	convert := NewConvert(c)
+
+	tempDir, _ := os.MkdirTemp("", "photoprism_test")
+	defer os.RemoveAll(tempDir)
+
+	rawFilePath := filepath.Join(tempDir, "test.raw")
+	os.WriteFile(rawFilePath, []byte("dummy raw data"), 0644)
+
+	mediaFile, err := NewMediaFile(rawFilePath)
+	assert.NoError(t, err)
+
+	fake := &fakeConverter{name: "fake", priority: 100, eligible: true}
+	convert.converters.Register(fake)
+
+	// cwebp isn't installed in the test environment, so ConvertToWebp
+	// still errors, but only after routing the RAW source through the
+	// registered RAW converter to produce a JPEG cwebp could read.
+	_ = convert.ConvertToWebp(mediaFile)
+	assert.True(t, fake.converted)
+
+	jpegFilePath := rawFilePath + ".jpg"
+	_, err = os.Stat(jpegFilePath)
+	assert.NoError(t, err)
+}
+
+func TestResolutionLimitError_Error(t *testing.T) {
+	err := &ResolutionLimitError{Megapixels: 120, Limit: 100}
+	assert.Equal(t, "convert: 120.0 MP exceeds the 100.0 MP resolution limit", err.Error())
+}
+
 func TestConvert_ConvertToJpeg(t *testing.T) {
 	c := config.TestConfig()
 	convert := NewConvert(c)
@@ -72,6 +129,150 @@ func TestConvert_CreateSidecarJson(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestConvert_CreateSidecarJson_CacheHit(t *testing.T) {
+	c := config.TestConfig()
+	convert := NewConvert(c)
+
+	tempDir, _ := os.MkdirTemp("", "photoprism_test")
+	defer os.RemoveAll(tempDir)
+	mediaFilePath := filepath.Join(tempDir, "test.jpg")
+	os.WriteFile(mediaFilePath, []byte("dummy jpg data"), 0644)
+
+	mediaFile, err := NewMediaFile(mediaFilePath)
+	assert.NoError(t, err)
+
+	// First call extracts metadata and populates the cache.
+	err = convert.CreateSidecarJson(mediaFile)
+	assert.NoError(t, err)
+
+	jsonFilePath := mediaFilePath + ".json"
+	original, err := os.ReadFile(jsonFilePath)
+	assert.NoError(t, err)
+
+	// Remove the sidecar but keep the cache, then reprocess the same bytes
+	// under a different file name to confirm the cached result is reused.
+	assert.NoError(t, os.Remove(jsonFilePath))
+
+	renamedPath := filepath.Join(tempDir, "renamed.jpg")
+	os.WriteFile(renamedPath, []byte("dummy jpg data"), 0644)
+
+	renamedFile, err := NewMediaFile(renamedPath)
+	assert.NoError(t, err)
+
+	err = convert.CreateSidecarJson(renamedFile)
+	assert.NoError(t, err)
+
+	cached, err := os.ReadFile(renamedPath + ".json")
+	assert.NoError(t, err)
+	assert.Equal(t, original, cached)
+}
+
+func TestConvert_CreateSidecarXmp(t *testing.T) {
+	c := config.TestConfig()
+	convert := NewConvert(c)
+
+	tempDir, _ := os.MkdirTemp("", "photoprism_test")
+	defer os.RemoveAll(tempDir)
+	mediaFilePath := filepath.Join(tempDir, "test.jpg")
+	os.WriteFile(mediaFilePath, []byte("dummy jpg data"), 0644)
+
+	mediaFile, err := NewMediaFile(mediaFilePath)
+	assert.NoError(t, err)
+
+	err = convert.CreateSidecarXmp(mediaFile)
+	assert.NoError(t, err)
+
+	xmpFilePath := mediaFilePath + ".xmp"
+	data, err := os.ReadFile(xmpFilePath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "x:xmpmeta")
+}
+
+func TestConvert_ConvertToJpeg_PassesNewerXmpSidecarToConverter(t *testing.T) {
+	c := config.TestConfig()
+	convert := NewConvert(c)
+
+	tempDir, _ := os.MkdirTemp("", "photoprism_test")
+	defer os.RemoveAll(tempDir)
+
+	rawFilePath := filepath.Join(tempDir, "test.raw")
+	os.WriteFile(rawFilePath, []byte("dummy raw data"), 0644)
+
+	xmpFilePath := rawFilePath + ".xmp"
+	os.WriteFile(xmpFilePath, []byte("<x:xmpmeta/>"), 0644)
+
+	future := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(xmpFilePath, future, future))
+
+	mediaFile, err := NewMediaFile(rawFilePath)
+	assert.NoError(t, err)
+
+	fake := &fakeConverter{name: "fake", priority: 100, eligible: true}
+	convert.converters.Register(fake)
+
+	err = convert.ConvertToJpeg(mediaFile)
+	assert.NoError(t, err)
+	assert.Equal(t, xmpFilePath, fake.gotOpts.XmpPath)
+}
+
+func TestConvert_ConvertToJpeg_IgnoresStaleXmpSidecar(t *testing.T) {
+	c := config.TestConfig()
+	convert := NewConvert(c)
+
+	tempDir, _ := os.MkdirTemp("", "photoprism_test")
+	defer os.RemoveAll(tempDir)
+
+	rawFilePath := filepath.Join(tempDir, "test.raw")
+	os.WriteFile(rawFilePath, []byte("dummy raw data"), 0644)
+
+	xmpFilePath := rawFilePath + ".xmp"
+	os.WriteFile(xmpFilePath, []byte("<x:xmpmeta/>"), 0644)
+
+	past := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(xmpFilePath, past, past))
+
+	mediaFile, err := NewMediaFile(rawFilePath)
+	assert.NoError(t, err)
+
+	fake := &fakeConverter{name: "fake", priority: 100, eligible: true}
+	convert.converters.Register(fake)
+
+	err = convert.ConvertToJpeg(mediaFile)
+	assert.NoError(t, err)
+	assert.Empty(t, fake.gotOpts.XmpPath)
+}
+
+// silentConverter reports success without writing anything to dstPath, the
+// way dcrawConverter/libRawConverter used to before they were fixed to
+// redirect their tools' stdout to dstPath.
+type silentConverter struct{}
+
+func (c *silentConverter) Name() string                  { return "silent" }
+func (c *silentConverter) CanConvert(mf *MediaFile) bool { return true }
+func (c *silentConverter) Priority() int                 { return 100 }
+func (c *silentConverter) Convert(ctx context.Context, mf *MediaFile, dstPath string, opts RawConvertOptions) error {
+	return nil
+}
+
+func TestConvert_ConvertToJpeg_FailsWhenConverterProducesNoOutput(t *testing.T) {
+	c := config.TestConfig()
+	convert := NewConvert(c)
+
+	tempDir, _ := os.MkdirTemp("", "photoprism_test")
+	defer os.RemoveAll(tempDir)
+
+	rawFilePath := filepath.Join(tempDir, "test.raw")
+	os.WriteFile(rawFilePath, []byte("dummy raw data"), 0644)
+
+	mediaFile, err := NewMediaFile(rawFilePath)
+	assert.NoError(t, err)
+
+	convert.converters.Register(&silentConverter{})
+
+	err = convert.ConvertToJpeg(mediaFile)
+	assert.Error(t, err)
+}
+
 func TestCalculateOptimalWorkers(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -1,6 +1,7 @@
 package photoprism
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -42,25 +43,12 @@ func IndexPhoto(conf *config.Config, fileIndexer *Indexer, filePath string, o In
 	// Detect faces and persons.
 	if !o.SkipFaces && !photoEntity.Skip() && photoEntity.HasID() && MediaFile().IsJpeg() {
 		face.SampleFromMedia(conf, MediaFile(), photoEntity, o.Force)
-	}
 
-	// Analyze photo with BRAINS if enabled
-	if conf.BrainsEnabled() && !photoEntity.Skip() && photoEntity.HasID() && MediaFile().IsJpeg() {
-		log.Debugf("indexer: analyzing %s with BRAINS", clean.Log(filePath))
-		
-		// Initialize BRAINS
-		brainsProcessor := brains.New(conf)
-		
-		// Get file path
-		originalPath := MediaFile().AbsPath
-		
-		// Process file with BRAINS
-		fileResult, err := brainsProcessor.ProcessFile(originalPath)
-		
-		if err != nil {
-			log.Warnf("indexer: BRAINS failed for %s: %s", clean.Log(filePath), err)
-		} else if fileResult != nil {
-			log.Debugf("indexer: BRAINS analysis complete for %s", clean.Log(filePath))
+		// Compute FaceNet embeddings for any markers the detector just
+		// created, so they're clusterable into people without waiting for a
+		// second pass over this photo.
+		if err := brains.New(conf).EmbedFaces(MediaFile().FileName(), photoEntity); err != nil {
+			log.Warnf("index: %s [faces] %s", clean.Log(filepath.Base(filePath)), err)
 		}
 	}
 
@@ -71,6 +59,20 @@ func IndexPhoto(conf *config.Config, fileIndexer *Indexer, filePath string, o In
 		return result
 	}
 
+	// Submit the saved photo to the BRAINS worker pool in the background if
+	// enabled, so indexing doesn't block on aesthetic/scene/object analysis;
+	// results are cached and persisted by the time the photo detail view is
+	// opened. It's queued after Save so the worker always resolves an ID
+	// that's already committed to the database. BRAINS itself now handles
+	// every format in SupportedFormats, not just JPEG: HEIC/RAW go through
+	// their Convert-produced JPEG sidecar, and videos are sampled into
+	// keyframes, so this no longer needs to filter out non-JPEG files.
+	if conf.BrainsEnabled() && !photoEntity.Skip() && photoEntity.HasID() {
+		log.Debugf("indexer: warming BRAINS cache for %s", clean.Log(filePath))
+
+		brains.New(conf).Warmer().Warm(context.Background(), []string{photoEntity.ID})
+	}
+
 	// ...existing code...
 
 	return result
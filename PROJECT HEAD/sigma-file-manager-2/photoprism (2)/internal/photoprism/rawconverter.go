@@ -0,0 +1,308 @@
+package photoprism
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+
+	"github.com/photoprism/photoprism/internal/config"
+	"github.com/photoprism/photoprism/pkg/fs"
+)
+
+// RawConvertOptions carries converter-specific parameters for
+// RawConverter.Convert. It's deliberately small; most behavior is
+// controlled by config, not by the caller.
+type RawConvertOptions struct {
+	Quality int
+	// XmpPath is an existing XMP sidecar to apply during conversion, so
+	// edits like crops and curves made since the last conversion are baked
+	// into the new output. Only darktableConverter currently honors it.
+	XmpPath string
+}
+
+// RawConverter decodes a RAW (or RAW-adjacent) original into dstPath.
+// ConverterRegistry picks among the converters a Convert has registered by
+// Priority and per-file CanConvert capability, so darktable, RawTherapee,
+// sips, dcraw, libraw and heif-convert can all be available at once without
+// ConvertToJpeg hardcoding which one wins.
+type RawConverter interface {
+	// Name identifies the converter for logging and the per-extension
+	// config override in ConverterRegistry.Select.
+	Name() string
+	// CanConvert reports whether this converter can handle mf at all.
+	CanConvert(mf *MediaFile) bool
+	// Convert converts mf to dstPath, respecting ctx cancellation.
+	Convert(ctx context.Context, mf *MediaFile, dstPath string, opts RawConvertOptions) error
+	// Priority ranks converters when more than one can handle a file;
+	// higher wins.
+	Priority() int
+}
+
+// runConverter shells out to name with args, the pattern every built-in
+// RawConverter.Convert shares for tools that write their own output file
+// given a destination path argument.
+func runConverter(ctx context.Context, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %v\nStderr: %s", name, err, stderr.String())
+	}
+
+	return nil
+}
+
+// runConverterToStdout shells out to name with args and writes whatever it
+// prints to stdout to dstPath, for tools like dcraw/dcraw_emu that decode
+// to stdout rather than accepting a destination path argument.
+func runConverterToStdout(ctx context.Context, name, dstPath string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %v\nStderr: %s", name, err, stderr.String())
+	}
+
+	if out.Len() == 0 {
+		return fmt.Errorf("%s: produced no output", name)
+	}
+
+	if err := os.WriteFile(dstPath, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("%s: failed to write %s: %v", name, dstPath, err)
+	}
+
+	return nil
+}
+
+// darktableConverter wraps darktable-cli.
+type darktableConverter struct {
+	exclude fs.ExtList
+}
+
+func newDarktableConverter(conf *config.Config) RawConverter {
+	return &darktableConverter{exclude: fs.NewExtList(conf.DarktableExclude())}
+}
+
+func (c *darktableConverter) Name() string { return "darktable" }
+
+func (c *darktableConverter) CanConvert(mf *MediaFile) bool {
+	return mf.IsRaw() && !c.exclude.Contains(mf.Extension())
+}
+
+func (c *darktableConverter) Convert(ctx context.Context, mf *MediaFile, dstPath string, opts RawConvertOptions) error {
+	if opts.XmpPath != "" {
+		return runConverter(ctx, "darktable-cli", mf.AbsPath, opts.XmpPath, dstPath)
+	}
+
+	return runConverter(ctx, "darktable-cli", mf.AbsPath, dstPath)
+}
+
+func (c *darktableConverter) Priority() int { return 80 }
+
+// rawTherapeeConverter wraps rawtherapee-cli.
+type rawTherapeeConverter struct {
+	exclude fs.ExtList
+}
+
+func newRawTherapeeConverter(conf *config.Config) RawConverter {
+	return &rawTherapeeConverter{exclude: fs.NewExtList(conf.RawTherapeeExclude())}
+}
+
+func (c *rawTherapeeConverter) Name() string { return "rawtherapee" }
+
+func (c *rawTherapeeConverter) CanConvert(mf *MediaFile) bool {
+	return mf.IsRaw() && !c.exclude.Contains(mf.Extension())
+}
+
+func (c *rawTherapeeConverter) Convert(ctx context.Context, mf *MediaFile, dstPath string, opts RawConvertOptions) error {
+	return runConverter(ctx, "rawtherapee-cli", "-o", dstPath, "-c", mf.AbsPath)
+}
+
+func (c *rawTherapeeConverter) Priority() int { return 85 }
+
+// sipsConverter wraps macOS's built-in sips tool. It only ever registers on
+// darwin, since sips doesn't exist anywhere else.
+type sipsConverter struct {
+	exclude fs.ExtList
+}
+
+func newSipsConverter(conf *config.Config) RawConverter {
+	return &sipsConverter{exclude: fs.NewExtList(conf.SipsExclude())}
+}
+
+func (c *sipsConverter) Name() string { return "sips" }
+
+func (c *sipsConverter) CanConvert(mf *MediaFile) bool {
+	return runtime.GOOS == "darwin" && mf.IsRaw() && !c.exclude.Contains(mf.Extension())
+}
+
+func (c *sipsConverter) Convert(ctx context.Context, mf *MediaFile, dstPath string, opts RawConvertOptions) error {
+	return runConverter(ctx, "sips", "-s", "format", "jpeg", mf.AbsPath, "--out", dstPath)
+}
+
+func (c *sipsConverter) Priority() int { return 50 }
+
+// dcrawConverter wraps dcraw, the lowest-common-denominator RAW decoder
+// used as a last resort when nothing more capable is installed.
+type dcrawConverter struct{}
+
+func newDcrawConverter(conf *config.Config) RawConverter {
+	return &dcrawConverter{}
+}
+
+func (c *dcrawConverter) Name() string { return "dcraw" }
+
+func (c *dcrawConverter) CanConvert(mf *MediaFile) bool {
+	return mf.IsRaw()
+}
+
+// Convert extracts the camera's embedded JPEG preview with "-e" and "-c"
+// (write to stdout instead of an auto-named sibling file) and writes it to
+// dstPath, since dcraw has no destination-path argument of its own.
+func (c *dcrawConverter) Convert(ctx context.Context, mf *MediaFile, dstPath string, opts RawConvertOptions) error {
+	return runConverterToStdout(ctx, "dcraw", dstPath, "-c", "-e", mf.AbsPath)
+}
+
+func (c *dcrawConverter) Priority() int { return 10 }
+
+// libRawConverter wraps libraw's unprocessed_raw/raw-identify-adjacent
+// dcraw_emu tool.
+type libRawConverter struct{}
+
+func newLibRawConverter(conf *config.Config) RawConverter {
+	return &libRawConverter{}
+}
+
+func (c *libRawConverter) Name() string { return "libraw" }
+
+func (c *libRawConverter) CanConvert(mf *MediaFile) bool {
+	return mf.IsRaw()
+}
+
+// Convert extracts the camera's embedded JPEG preview the same way
+// dcrawConverter does, since dcraw_emu emulates dcraw's CLI and has no
+// destination-path argument of its own either.
+func (c *libRawConverter) Convert(ctx context.Context, mf *MediaFile, dstPath string, opts RawConvertOptions) error {
+	return runConverterToStdout(ctx, "dcraw_emu", dstPath, "-c", "-e", "-w", mf.AbsPath)
+}
+
+func (c *libRawConverter) Priority() int { return 20 }
+
+// heifConvertConverter wraps libheif's heif-convert, for HEIC/HEIF
+// originals rather than classic RAW formats.
+type heifConvertConverter struct{}
+
+func newHeifConvertConverter(conf *config.Config) RawConverter {
+	return &heifConvertConverter{}
+}
+
+func (c *heifConvertConverter) Name() string { return "heif-convert" }
+
+func (c *heifConvertConverter) CanConvert(mf *MediaFile) bool {
+	return mf.IsHeic()
+}
+
+func (c *heifConvertConverter) Convert(ctx context.Context, mf *MediaFile, dstPath string, opts RawConvertOptions) error {
+	return runConverter(ctx, "heif-convert", mf.AbsPath, dstPath)
+}
+
+func (c *heifConvertConverter) Priority() int { return 60 }
+
+// converterBinary maps a RawConverter to the executable ConverterRegistry
+// looks for on PATH before registering it.
+var converterBinary = map[string]string{
+	"darktable":    "darktable-cli",
+	"rawtherapee":  "rawtherapee-cli",
+	"sips":         "sips",
+	"dcraw":        "dcraw",
+	"libraw":       "dcraw_emu",
+	"heif-convert": "heif-convert",
+}
+
+// isBinaryAvailable reports whether name can be found on PATH.
+func isBinaryAvailable(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// ConverterRegistry holds the RawConverters NewConvert detected as
+// available on this host at startup, sorted by Priority so ConvertToJpeg
+// can pick the best one capable of handling a given file.
+type ConverterRegistry struct {
+	conf       *config.Config
+	converters []RawConverter
+}
+
+// NewConverterRegistry probes PATH for every built-in converter's binary
+// and registers the ones that are actually installed.
+func NewConverterRegistry(conf *config.Config) *ConverterRegistry {
+	r := &ConverterRegistry{conf: conf}
+
+	candidates := []RawConverter{
+		newRawTherapeeConverter(conf),
+		newDarktableConverter(conf),
+		newHeifConvertConverter(conf),
+		newSipsConverter(conf),
+		newLibRawConverter(conf),
+		newDcrawConverter(conf),
+	}
+
+	for _, c := range candidates {
+		if isBinaryAvailable(converterBinary[c.Name()]) {
+			r.converters = append(r.converters, c)
+		}
+	}
+
+	sort.SliceStable(r.converters, func(i, j int) bool {
+		return r.converters[i].Priority() > r.converters[j].Priority()
+	})
+
+	return r
+}
+
+// Register adds c to the registry directly, bypassing binary detection —
+// useful for tests, and for wiring up a converter that isn't backed by a
+// local executable at all.
+func (r *ConverterRegistry) Register(c RawConverter) {
+	r.converters = append(r.converters, c)
+
+	sort.SliceStable(r.converters, func(i, j int) bool {
+		return r.converters[i].Priority() > r.converters[j].Priority()
+	})
+}
+
+// Converters returns every registered converter, highest priority first.
+func (r *ConverterRegistry) Converters() []RawConverter {
+	return r.converters
+}
+
+// Select returns the best registered converter for mf, honoring a
+// per-extension config override before falling back to priority order. It
+// returns nil if no registered converter can handle mf.
+func (r *ConverterRegistry) Select(mf *MediaFile) RawConverter {
+	if preferred := r.conf.PreferredRawConverter(mf.Extension()); preferred != "" {
+		for _, c := range r.converters {
+			if c.Name() == preferred && c.CanConvert(mf) {
+				return c
+			}
+		}
+	}
+
+	for _, c := range r.converters {
+		if c.CanConvert(mf) {
+			return c
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package photoprism
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/photoprism/photoprism/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConverter is a RawConverter stand-in for testing ConverterRegistry
+// selection order without shelling out to a real binary.
+type fakeConverter struct {
+	name      string
+	priority  int
+	eligible  bool
+	converted bool
+	gotOpts   RawConvertOptions
+}
+
+func (f *fakeConverter) Name() string { return f.name }
+
+func (f *fakeConverter) CanConvert(mf *MediaFile) bool { return f.eligible }
+
+func (f *fakeConverter) Convert(ctx context.Context, mf *MediaFile, dstPath string, opts RawConvertOptions) error {
+	f.converted = true
+	f.gotOpts = opts
+	return os.WriteFile(dstPath, []byte("fake jpeg data"), 0644)
+}
+
+func (f *fakeConverter) Priority() int { return f.priority }
+
+func testRawFile(t *testing.T) *MediaFile {
+	tempDir, _ := os.MkdirTemp("", "photoprism_test")
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+
+	rawFilePath := filepath.Join(tempDir, "test.raw")
+	os.WriteFile(rawFilePath, []byte("dummy raw data"), 0644)
+
+	mediaFile, err := NewMediaFile(rawFilePath)
+	assert.NoError(t, err)
+
+	return mediaFile
+}
+
+func TestConverterRegistry_SelectPicksHighestPriority(t *testing.T) {
+	c := config.TestConfig()
+	registry := NewConverterRegistry(c)
+
+	low := &fakeConverter{name: "low", priority: 1, eligible: true}
+	high := &fakeConverter{name: "high", priority: 100, eligible: true}
+
+	registry.Register(low)
+	registry.Register(high)
+
+	selected := registry.Select(testRawFile(t))
+
+	assert.NotNil(t, selected)
+	assert.Equal(t, "high", selected.Name())
+}
+
+func TestConverterRegistry_SelectSkipsIneligibleConverter(t *testing.T) {
+	c := config.TestConfig()
+	registry := NewConverterRegistry(c)
+
+	ineligible := &fakeConverter{name: "ineligible", priority: 100, eligible: false}
+	fallback := &fakeConverter{name: "fallback", priority: 1, eligible: true}
+
+	registry.Register(ineligible)
+	registry.Register(fallback)
+
+	selected := registry.Select(testRawFile(t))
+
+	assert.NotNil(t, selected)
+	assert.Equal(t, "fallback", selected.Name())
+}
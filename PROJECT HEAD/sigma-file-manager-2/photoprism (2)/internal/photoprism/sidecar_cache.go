@@ -0,0 +1,73 @@
+package photoprism
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/photoprism/photoprism/internal/config"
+)
+
+// sidecarCacheDir is the subdirectory under conf.CachePath() that holds
+// cached ExifTool JSON sidecars, keyed by source file hash rather than path,
+// the way BRAINS caches its analysis results under CachePath() too.
+const sidecarCacheDir = "sidecar-json"
+
+// SidecarCache stores rendered JSON sidecars keyed by the hash of the
+// original file they were extracted from, so renaming or moving an original
+// doesn't force ExifTool to run again on the next index/import scan.
+type SidecarCache struct {
+	dir string
+}
+
+// NewSidecarCache returns a SidecarCache rooted at conf.CachePath().
+func NewSidecarCache(conf *config.Config) *SidecarCache {
+	return &SidecarCache{
+		dir: filepath.Join(conf.CachePath(), sidecarCacheDir),
+	}
+}
+
+// CachePath returns the path a JSON sidecar for hash would be stored at.
+func (c *SidecarCache) CachePath(hash string) string {
+	return filepath.Join(c.dir, hash+".json")
+}
+
+// Get returns the cached JSON sidecar for hash, if one exists.
+func (c *SidecarCache) Get(hash string) ([]byte, bool) {
+	data, err := os.ReadFile(c.CachePath(hash))
+	if err != nil {
+		return nil, false
+	}
+
+	return data, true
+}
+
+// Put stores data as the cached JSON sidecar for hash.
+func (c *SidecarCache) Put(hash string, data []byte) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.CachePath(hash), data, 0o644)
+}
+
+// hashFile returns the hex-encoded SHA-1 digest of the file at fileName,
+// used as the cache key so CreateSidecarJson can recognize an unchanged
+// original after it's been renamed or moved.
+func hashFile(fileName string) (string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha1.New()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
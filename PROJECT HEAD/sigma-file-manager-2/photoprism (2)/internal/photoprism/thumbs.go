@@ -0,0 +1,111 @@
+package photoprism
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"github.com/photoprism/photoprism/internal/config"
+	"github.com/photoprism/photoprism/internal/mutex"
+	"github.com/photoprism/photoprism/pkg/fs"
+)
+
+// thumbsStateCommand is the entity.ConvertState command key Thumbs.Start
+// records completed files under, distinguishing them from ones completed
+// by Convert.Start.
+const thumbsStateCommand = "thumbs"
+
+// Thumbs generates cached preview images for originals. It shares Convert's
+// deterministic, resumable directory walk so large libraries can be
+// interrupted and resumed the same way `photoprism convert` already can.
+type Thumbs struct {
+	conf             *config.Config
+	supportedFormats fs.ExtList
+}
+
+// NewThumbs returns a new thumbnail generation worker.
+func NewThumbs(conf *config.Config) *Thumbs {
+	return &Thumbs{
+		conf:             conf,
+		supportedFormats: fs.NewExtList(SupportedFormats),
+	}
+}
+
+// Start generates thumbnails for every supported file in dir, in stable,
+// lexically sorted path order, honoring opt.StartPath/opt.Limit/opt.Resume
+// the same way Convert.Start does.
+func (t *Thumbs) Start(dir string, ext []string, opt ConvertOptions) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("thumbs: %s (panic)\nstack: %s", r, debug.Stack())
+			log.Error(err)
+		}
+	}()
+
+	if err = mutex.IndexWorker.Start(); err != nil {
+		return err
+	}
+
+	defer mutex.IndexWorker.Stop()
+
+	files, err := walkSortedFiles(dir, t.supportedFormats)
+	if err != nil {
+		log.Infof("thumbs: %s", err)
+	}
+
+	files = applyConvertOptions(files, thumbsStateCommand, opt)
+
+	log.Infof("thumbs: processing %d files in sorted order", len(files))
+
+	numWorkers := CalculateOptimalWorkers(t.conf.IndexWorkers())
+	jobs := make(chan string)
+
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for fileName := range jobs {
+				t.processFile(fileName, opt.Force)
+			}
+		}()
+	}
+
+	for _, fileName := range files {
+		if mutex.IndexWorker.Canceled() {
+			break
+		}
+
+		jobs <- fileName
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return nil
+}
+
+// processFile creates thumbnails for a single file, recovering from panics
+// so one bad file can't abort the whole sorted-order run.
+func (t *Thumbs) processFile(fileName string, force bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("thumbs: %s (panic)\nstack: %s", r, debug.Stack())
+		}
+	}()
+
+	f, err := NewMediaFile(fileName)
+
+	if err != nil || f.Empty() || !f.IsMedia() {
+		return
+	}
+
+	if _, err := f.CreateThumbnails(t.conf.ThumbPath(), force); err != nil {
+		log.Errorf("thumbs: failed to create thumbnails for %s: %v", fileName, err)
+		return
+	}
+
+	recordConvertDone(thumbsStateCommand, fileName)
+}
@@ -0,0 +1,111 @@
+package photoprism
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/karrick/godirwalk"
+
+	"github.com/photoprism/photoprism/internal/entity"
+	"github.com/photoprism/photoprism/pkg/clean"
+	"github.com/photoprism/photoprism/pkg/fs"
+	"github.com/photoprism/photoprism/pkg/txt"
+)
+
+// ConvertOptions configures a resumable Convert.Start or Thumbs.Start run.
+type ConvertOptions struct {
+	// Force reprocesses files that already have a converted result.
+	Force bool
+	// Resume skips files already recorded as done in a previous run.
+	Resume bool
+	// StartPath resumes a sorted walk from this path onward.
+	StartPath string
+	// Limit caps how many files are processed in this run, 0 for no limit.
+	Limit int
+	// Format is the output format ("jpeg", "webp" or "avif") Convert.Start
+	// encodes RAW/WebP/AVIF originals to, defaulting to FormatJpeg.
+	Format string
+}
+
+// walkSortedFiles returns every file under dir matching supported, in
+// stable lexical path order rather than filesystem walk order, so repeated
+// runs over an unchanged archive produce identical, diffable logs and can
+// be split into reproducible --start/--limit ranges.
+func walkSortedFiles(dir string, supported fs.ExtList) (files []string, err error) {
+	done := make(fs.Done)
+	ignore := fs.NewIgnoreList(fs.PPIgnoreFilename, true, false)
+
+	if err := ignore.Path(dir); err != nil {
+		log.Infof("convert: %s", err)
+	}
+
+	ignore.Log = func(fileName string) {
+		log.Infof("convert: ignoring %s", clean.Log(filepath.Base(fileName)))
+	}
+
+	err = godirwalk.Walk(dir, &godirwalk.Options{
+		ErrorCallback: func(fileName string, err error) godirwalk.ErrorAction {
+			return godirwalk.SkipNode
+		},
+		Callback: func(fileName string, info *godirwalk.Dirent) error {
+			isDir, _ := info.IsDirOrSymlinkToDir()
+			isSymlink := info.IsSymlink()
+
+			if skip, result := fs.SkipWalk(fileName, isDir, isSymlink, done, ignore); skip {
+				return result
+			}
+
+			ext := txt.Lower(filepath.Ext(fileName))
+
+			if !supported.Contains(ext) {
+				return nil
+			}
+
+			files = append(files, fileName)
+
+			return nil
+		},
+		Unsorted:            true,
+		FollowSymbolicLinks: true,
+	})
+
+	sort.Strings(files)
+
+	return files, err
+}
+
+// applyConvertOptions narrows a sorted file list down to opt's --start and
+// --limit range, and drops files already recorded as done for command when
+// opt.Resume is set.
+func applyConvertOptions(files []string, command string, opt ConvertOptions) []string {
+	if opt.StartPath != "" {
+		i := sort.SearchStrings(files, opt.StartPath)
+		files = files[i:]
+	}
+
+	if opt.Resume {
+		remaining := files[:0]
+
+		for _, fileName := range files {
+			if !entity.ConvertDone(command, fileName) {
+				remaining = append(remaining, fileName)
+			}
+		}
+
+		files = remaining
+	}
+
+	if opt.Limit > 0 && opt.Limit < len(files) {
+		files = files[:opt.Limit]
+	}
+
+	return files
+}
+
+// recordConvertDone marks fileName as completed for command, logging a
+// warning rather than failing the run if the state table can't be updated.
+func recordConvertDone(command, fileName string) {
+	if err := entity.MarkConvertDone(command, fileName); err != nil {
+		log.Warnf("convert: failed to record completion for %s: %v", fileName, err)
+	}
+}
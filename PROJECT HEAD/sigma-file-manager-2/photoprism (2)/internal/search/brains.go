@@ -1,14 +1,25 @@
 package search
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/jinzhu/gorm"
+
 	"github.com/photoprism/photoprism/internal/entity"
 	"github.com/photoprism/photoprism/internal/form"
 	"github.com/photoprism/photoprism/pkg/txt"
 )
 
+// brainsSearchDefaultCount and brainsSearchMaxCount bound Find's page size
+// the same way the rest of the search package caps SearchPhotos.Count.
+const (
+	brainsSearchDefaultCount = 100
+	brainsSearchMaxCount     = 1000
+)
+
 // BrainsSearch represents a search for photos based on BRAINS analysis results.
 type BrainsSearch struct {
 	Query *Query
@@ -19,222 +30,427 @@ func NewBrainsSearch(query *Query) *BrainsSearch {
 	return &BrainsSearch{Query: query}
 }
 
-// AestheticScore searches for photos based on aesthetic score range.
-func (s *BrainsSearch) AestheticScore(min, max float32) (results PhotoResults, err error) {
-	if min < 0 || min > 10 {
-		min = 0
+// Find builds a single query composing every predicate set on f, so a
+// caller can combine attributes ("outdoor" + "golden-hour" +
+// "aesthetic>7") instead of intersecting several single-attribute result
+// sets by hand. It honors the standard Query.Filters, pages results via
+// f.Count/f.Offset, supports the "aesthetic", "newest" and "relevance"
+// sort orders, and returns the total number of matches (ignoring
+// Count/Offset) alongside the requested page.
+func (s *BrainsSearch) Find(f form.SearchBrains) (results PhotoResults, count int, err error) {
+	q := s.Query.db.Table("photos").
+		Select("photos.*").
+		Joins("JOIN brains_results ON brains_results.photo_id = photos.id")
+
+	if q, err = s.applyPredicates(q, f); err != nil {
+		return results, 0, err
 	}
 
-	if max < 0 || max > 10 {
-		max = 10
+	if err = s.Query.Filters(q); err != nil {
+		return results, 0, err
 	}
 
-	// Search query
-	q := s.Query.db.Table("photos").
-		Select("photos.*").
-		Joins("JOIN brains_results ON brains_results.photo_id = photos.id").
-		Where("brains_results.aesthetic_score BETWEEN ? AND ?", min, max)
+	var total int
+	if err = q.Count(&total).Error; err != nil {
+		return results, 0, err
+	}
 
-	// Apply standard filters
-	if err = s.Query.Filters(q); err != nil {
-		return results, err
+	limit := f.Count
+	if limit <= 0 {
+		limit = brainsSearchDefaultCount
+	} else if limit > brainsSearchMaxCount {
+		limit = brainsSearchMaxCount
 	}
 
-	// Fetch results
+	q = s.applyOrder(q, f.Order).Limit(limit).Offset(f.Offset)
+
 	if err = q.Scan(&results).Error; err != nil {
-		return results, err
+		return results, 0, err
 	}
 
-	return results, nil
+	return results, total, nil
 }
 
-// SceneType searches for photos based on scene type.
-func (s *BrainsSearch) SceneType(sceneType string) (results PhotoResults, err error) {
-	if sceneType == "" {
-		return results, fmt.Errorf("scene type cannot be empty")
+// applyPredicates adds a Where clause to q for every attribute field set on
+// f, so an unset field simply contributes nothing to the query.
+func (s *BrainsSearch) applyPredicates(q *gorm.DB, f form.SearchBrains) (*gorm.DB, error) {
+	if f.AestheticMin > 0 || f.AestheticMax > 0 {
+		min, max := f.AestheticMin, f.AestheticMax
+
+		if min < 0 || min > 10 {
+			min = 0
+		}
+
+		if max <= 0 || max > 10 {
+			max = 10
+		}
+
+		q = q.Where("brains_results.aesthetic_score BETWEEN ? AND ?", min, max)
 	}
 
-	sceneType = strings.ToLower(strings.TrimSpace(sceneType))
+	if f.SceneType != "" {
+		q = q.Where("LOWER(brains_results.scene_type) = ?", txt.Lower(strings.TrimSpace(f.SceneType)))
+	}
 
-	// Search query
-	q := s.Query.db.Table("photos").
-		Select("photos.*").
-		Joins("JOIN brains_results ON brains_results.photo_id = photos.id").
-		Where("LOWER(brains_results.scene_type) = ?", sceneType)
+	if f.IndoorOutdoor != "" {
+		setting := txt.Lower(strings.TrimSpace(f.IndoorOutdoor))
 
-	// Apply standard filters
-	if err = s.Query.Filters(q); err != nil {
-		return results, err
+		if setting != "indoor" && setting != "outdoor" {
+			return q, fmt.Errorf("setting must be 'indoor' or 'outdoor'")
+		}
+
+		q = q.Where("LOWER(brains_results.indoor_outdoor) = ?", setting)
 	}
 
-	// Fetch results
-	if err = q.Scan(&results).Error; err != nil {
-		return results, err
+	if f.TimeOfDay != "" {
+		q = q.Where("LOWER(brains_results.time_of_day) = ?", txt.Lower(strings.TrimSpace(f.TimeOfDay)))
 	}
 
-	return results, nil
-}
+	if f.Weather != "" {
+		q = q.Where("LOWER(brains_results.weather) = ?", txt.Lower(strings.TrimSpace(f.Weather)))
+	}
 
-// IndoorOutdoor searches for photos based on indoor/outdoor classification.
-func (s *BrainsSearch) IndoorOutdoor(setting string) (results PhotoResults, err error) {
-	setting = strings.ToLower(strings.TrimSpace(setting))
+	if f.Keyword != "" {
+		ids, err := s.keywordPhotoIDs(f.Keyword)
+		if err != nil {
+			return q, err
+		}
+
+		if len(ids) == 0 {
+			q = q.Where("1 = 0")
+		} else {
+			q = q.Where("photos.id IN (?)", ids)
+		}
+	}
 
-	if setting != "indoor" && setting != "outdoor" {
-		return results, fmt.Errorf("setting must be 'indoor' or 'outdoor'")
+	if f.Object != "" {
+		ids, err := s.objectPhotoIDs(f.Object, f.ObjectMinConfidence)
+		if err != nil {
+			return q, err
+		}
+
+		if len(ids) == 0 {
+			q = q.Where("1 = 0")
+		} else {
+			q = q.Where("photos.id IN (?)", ids)
+		}
 	}
 
-	// Search query
-	q := s.Query.db.Table("photos").
-		Select("photos.*").
-		Joins("JOIN brains_results ON brains_results.photo_id = photos.id").
-		Where("LOWER(brains_results.indoor_outdoor) = ?", setting)
+	if f.Emotion != "" {
+		ids, err := s.emotionPhotoIDs(f.Emotion, f.EmotionMin)
+		if err != nil {
+			return q, err
+		}
+
+		if len(ids) == 0 {
+			// Nothing matched the emotion filter, so short-circuit to an
+			// always-false condition instead of falling through to an
+			// unfiltered result set.
+			q = q.Where("1 = 0")
+		} else {
+			q = q.Where("photos.id IN (?)", ids)
+		}
+	}
 
-	// Apply standard filters
-	if err = s.Query.Filters(q); err != nil {
-		return results, err
+	if f.Objects != "" {
+		ids, err := s.multiValuePhotoIDs(f.Objects, s.objectConfidenceIDs)
+		if err != nil {
+			return q, err
+		}
+
+		if len(ids) == 0 {
+			q = q.Where("1 = 0")
+		} else {
+			q = q.Where("photos.id IN (?)", ids)
+		}
 	}
 
-	// Fetch results
-	if err = q.Scan(&results).Error; err != nil {
-		return results, err
+	if f.Keywords != "" {
+		ids, err := s.multiValuePhotoIDs(f.Keywords, func(keyword string, _ float32) ([]string, error) {
+			return s.keywordPhotoIDs(keyword)
+		})
+		if err != nil {
+			return q, err
+		}
+
+		if len(ids) == 0 {
+			q = q.Where("1 = 0")
+		} else {
+			q = q.Where("photos.id IN (?)", ids)
+		}
+	}
+
+	if f.Emotions != "" {
+		ids, err := s.multiValuePhotoIDs(f.Emotions, s.emotionPhotoIDs)
+		if err != nil {
+			return q, err
+		}
+
+		if len(ids) == 0 {
+			q = q.Where("1 = 0")
+		} else {
+			q = q.Where("photos.id IN (?)", ids)
+		}
 	}
 
-	return results, nil
+	return q, nil
 }
 
-// TimeOfDay searches for photos based on time of day analysis.
-func (s *BrainsSearch) TimeOfDay(timeOfDay string) (results PhotoResults, err error) {
-	timeOfDay = strings.ToLower(strings.TrimSpace(timeOfDay))
+// objectConfidenceIDs resolves a single "label" or "label:confidence" entry
+// to photo IDs, giving multiValuePhotoIDs a uniform signature to call it
+// with alongside keywordPhotoIDs and emotionPhotoIDs.
+func (s *BrainsSearch) objectConfidenceIDs(label string, minConfidence float32) ([]string, error) {
+	return s.objectPhotoIDs(label, minConfidence)
+}
 
-	if timeOfDay == "" {
-		return results, fmt.Errorf("time of day cannot be empty")
+// multiValuePhotoIDs splits value on commas, resolves each entry (optionally
+// suffixed with ":<min>", e.g. "cat:0.8") through resolve, and returns the
+// union of every entry's photo IDs, so Objects/Keywords/Emotions match a
+// photo satisfying any one of several comma-separated criteria.
+func (s *BrainsSearch) multiValuePhotoIDs(value string, resolve func(name string, min float32) ([]string, error)) (ids []string, err error) {
+	seen := make(map[string]bool)
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, min := entry, float32(0)
+
+		if i := strings.LastIndex(entry, ":"); i > 0 {
+			if parsed, parseErr := strconv.ParseFloat(entry[i+1:], 32); parseErr == nil {
+				name, min = entry[:i], float32(parsed)
+			}
+		}
+
+		entryIDs, resolveErr := resolve(name, min)
+		if resolveErr != nil {
+			return nil, resolveErr
+		}
+
+		for _, id := range entryIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
 	}
 
-	// Search query
-	q := s.Query.db.Table("photos").
-		Select("photos.*").
-		Joins("JOIN brains_results ON brains_results.photo_id = photos.id").
-		Where("LOWER(brains_results.time_of_day) = ?", timeOfDay)
+	return ids, nil
+}
 
-	// Apply standard filters
-	if err = s.Query.Filters(q); err != nil {
-		return results, err
+// applyOrder applies the sort order order recognizes to q: "aesthetic" or
+// "aesthetic_score" for highest-scoring first, "composition" for the best
+// composition sub-score first, "newest" for most recently taken first,
+// "processed_at" for most recently analyzed first, and "relevance" (the
+// default, also used for anything unrecognized) for whatever order the
+// joined rows already come back in.
+func (s *BrainsSearch) applyOrder(q *gorm.DB, order string) *gorm.DB {
+	switch txt.Lower(strings.TrimSpace(order)) {
+	case "aesthetic", "aesthetic_score":
+		return q.Order("brains_results.aesthetic_score DESC")
+	case "composition":
+		return q.Order("brains_results.composition DESC")
+	case "newest":
+		return q.Order("photos.taken_at DESC")
+	case "processed_at":
+		return q.Order("brains_results.processed_at DESC")
+	default:
+		return q
 	}
+}
 
-	// Fetch results
-	if err = q.Scan(&results).Error; err != nil {
-		return results, err
+// emotionPhotoIDs returns the IDs of photos whose BRAINS emotion analysis
+// scores name at least min. Emotions isn't its own column, so a LIKE
+// prefilter narrows the candidates before each one's JSON is decoded and
+// checked in Go.
+func (s *BrainsSearch) emotionPhotoIDs(name string, min float32) (ids []string, err error) {
+	name = txt.Lower(strings.TrimSpace(name))
+
+	var candidates []struct {
+		PhotoID  string
+		Emotions string
+	}
+
+	q := s.Query.db.Table("brains_results").
+		Select("photo_id, emotions").
+		Where("emotions LIKE ?", "%\""+name+"\"%")
+
+	if err = q.Scan(&candidates).Error; err != nil {
+		return nil, err
 	}
 
-	return results, nil
+	for _, candidate := range candidates {
+		var emotions map[string]float32
+		if err := json.Unmarshal([]byte(candidate.Emotions), &emotions); err != nil {
+			continue
+		}
+
+		if score, ok := emotions[name]; ok && score >= min {
+			ids = append(ids, candidate.PhotoID)
+		}
+	}
+
+	return ids, nil
 }
 
-// Weather searches for photos based on weather analysis.
-func (s *BrainsSearch) Weather(weather string) (results PhotoResults, err error) {
-	weather = strings.ToLower(strings.TrimSpace(weather))
+// keywordPhotoIDs returns the IDs of photos with a BRAINS keyword starting
+// with keyword, joining the indexed brains_keywords table instead of
+// scanning brains_results.keywords with LIKE.
+func (s *BrainsSearch) keywordPhotoIDs(keyword string) (ids []string, err error) {
+	keyword = txt.Lower(strings.TrimSpace(keyword))
 
-	if weather == "" {
-		return results, fmt.Errorf("weather cannot be empty")
+	q := s.Query.db.Table("brains_keywords").
+		Where("keyword LIKE ?", keyword+"%")
+
+	if err = q.Pluck("DISTINCT photo_id", &ids).Error; err != nil {
+		return nil, err
 	}
 
-	// Search query
-	q := s.Query.db.Table("photos").
-		Select("photos.*").
-		Joins("JOIN brains_results ON brains_results.photo_id = photos.id").
-		Where("LOWER(brains_results.weather) = ?", weather)
+	return ids, nil
+}
 
-	// Apply standard filters
-	if err = s.Query.Filters(q); err != nil {
-		return results, err
+// objectPhotoIDs returns the IDs of photos with a BRAINS-detected object
+// matching query, at least minConfidence confident, joining the indexed
+// brains_objects table instead of scanning brains_results.object_results
+// with LIKE. query may combine two labels with a single " AND " (all
+// labels must be present) or " OR " (any label matches); anything else is
+// treated as one label.
+func (s *BrainsSearch) objectPhotoIDs(query string, minConfidence float32) (ids []string, err error) {
+	query = strings.TrimSpace(query)
+
+	var labels []string
+	requireAll := false
+
+	switch {
+	case strings.Contains(query, " AND "):
+		labels = strings.Split(query, " AND ")
+		requireAll = true
+	case strings.Contains(query, " OR "):
+		labels = strings.Split(query, " OR ")
+	default:
+		labels = []string{query}
 	}
 
-	// Fetch results
-	if err = q.Scan(&results).Error; err != nil {
-		return results, err
+	for i, label := range labels {
+		labels[i] = txt.Lower(strings.TrimSpace(label))
 	}
 
-	return results, nil
-}
+	q := s.Query.db.Table("brains_objects").Where("label IN (?)", labels)
 
-// Keyword searches for photos based on BRAINS-extracted keywords.
-func (s *BrainsSearch) Keyword(keyword string) (results PhotoResults, err error) {
-	if keyword == "" {
-		return results, fmt.Errorf("keyword cannot be empty")
+	if minConfidence > 0 {
+		q = q.Where("confidence >= ?", minConfidence)
 	}
 
-	keyword = strings.ToLower(strings.TrimSpace(keyword))
-	
-	// Search query - use LIKE for substring search
-	q := s.Query.db.Table("photos").
-		Select("photos.*").
-		Joins("JOIN brains_results ON brains_results.photo_id = photos.id").
-		Where("brains_results.keywords LIKE ?", "%"+keyword+"%")
+	q = q.Group("photo_id")
 
-	// Apply standard filters
-	if err = s.Query.Filters(q); err != nil {
-		return results, err
+	if requireAll {
+		q = q.Having("COUNT(DISTINCT label) = ?", len(labels))
 	}
 
-	// Fetch results
-	if err = q.Scan(&results).Error; err != nil {
-		return results, err
+	if err = q.Pluck("photo_id", &ids).Error; err != nil {
+		return nil, err
 	}
 
-	return results, nil
+	return ids, nil
 }
 
-// Object searches for photos based on detected objects.
-func (s *BrainsSearch) Object(objectName string) (results PhotoResults, err error) {
-	if objectName == "" {
-		return results, fmt.Errorf("object name cannot be empty")
+// AestheticScore searches for photos based on aesthetic score range. It's a
+// thin wrapper around Find, kept for backward compatibility.
+func (s *BrainsSearch) AestheticScore(min, max float32) (results PhotoResults, err error) {
+	results, _, err = s.Find(form.SearchBrains{AestheticMin: min, AestheticMax: max})
+	return results, err
+}
+
+// SceneType searches for photos based on scene type. It's a thin wrapper
+// around Find, kept for backward compatibility.
+func (s *BrainsSearch) SceneType(sceneType string) (results PhotoResults, err error) {
+	if sceneType == "" {
+		return results, fmt.Errorf("scene type cannot be empty")
 	}
 
-	objectName = strings.ToLower(strings.TrimSpace(objectName))
-	
-	// Search query - use JSON-style LIKE search for object detection results
-	q := s.Query.db.Table("photos").
-		Select("photos.*").
-		Joins("JOIN brains_results ON brains_results.photo_id = photos.id").
-		Where("brains_results.object_results LIKE ?", "%\"label\":\""+objectName+"\"%")
+	results, _, err = s.Find(form.SearchBrains{SceneType: sceneType})
+	return results, err
+}
 
-	// Apply standard filters
-	if err = s.Query.Filters(q); err != nil {
-		return results, err
+// IndoorOutdoor searches for photos based on indoor/outdoor classification.
+// It's a thin wrapper around Find, kept for backward compatibility.
+func (s *BrainsSearch) IndoorOutdoor(setting string) (results PhotoResults, err error) {
+	results, _, err = s.Find(form.SearchBrains{IndoorOutdoor: setting})
+	return results, err
+}
+
+// TimeOfDay searches for photos based on time of day analysis. It's a thin
+// wrapper around Find, kept for backward compatibility.
+func (s *BrainsSearch) TimeOfDay(timeOfDay string) (results PhotoResults, err error) {
+	if timeOfDay == "" {
+		return results, fmt.Errorf("time of day cannot be empty")
 	}
 
-	// Fetch results
-	if err = q.Scan(&results).Error; err != nil {
-		return results, err
+	results, _, err = s.Find(form.SearchBrains{TimeOfDay: timeOfDay})
+	return results, err
+}
+
+// Weather searches for photos based on weather analysis. It's a thin
+// wrapper around Find, kept for backward compatibility.
+func (s *BrainsSearch) Weather(weather string) (results PhotoResults, err error) {
+	if weather == "" {
+		return results, fmt.Errorf("weather cannot be empty")
 	}
 
-	return results, nil
+	results, _, err = s.Find(form.SearchBrains{Weather: weather})
+	return results, err
 }
 
-// BestAesthetic returns photos with the highest aesthetic scores.
-func (s *BrainsSearch) BestAesthetic(limit int) (results PhotoResults, err error) {
-	if limit <= 0 {
-		limit = 20
-	} else if limit > 1000 {
-		limit = 1000
+// Emotion searches for photos whose BRAINS emotion analysis scores name at
+// least min. It's a thin wrapper around Find, kept for backward
+// compatibility.
+func (s *BrainsSearch) Emotion(name string, min float32) (results PhotoResults, err error) {
+	if name == "" {
+		return results, fmt.Errorf("emotion name cannot be empty")
 	}
 
-	// Search query
-	q := s.Query.db.Table("photos").
-		Select("photos.*").
-		Joins("JOIN brains_results ON brains_results.photo_id = photos.id").
-		Where("brains_results.aesthetic_score > 0").
-		Order("brains_results.aesthetic_score DESC").
-		Limit(limit)
+	results, _, err = s.Find(form.SearchBrains{Emotion: name, EmotionMin: min})
+	return results, err
+}
 
-	// Apply standard filters
-	if err = s.Query.Filters(q); err != nil {
-		return results, err
+// Keyword searches for photos based on BRAINS-extracted keywords. It's a
+// thin wrapper around Find, kept for backward compatibility.
+func (s *BrainsSearch) Keyword(keyword string) (results PhotoResults, err error) {
+	if keyword == "" {
+		return results, fmt.Errorf("keyword cannot be empty")
 	}
 
-	// Fetch results
-	if err = q.Scan(&results).Error; err != nil {
-		return results, err
+	results, _, err = s.Find(form.SearchBrains{Keyword: keyword})
+	return results, err
+}
+
+// Object searches for photos based on detected objects. It's a thin
+// wrapper around Find, kept for backward compatibility.
+func (s *BrainsSearch) Object(objectName string) (results PhotoResults, err error) {
+	if objectName == "" {
+		return results, fmt.Errorf("object name cannot be empty")
 	}
 
-	return results, nil
+	results, _, err = s.Find(form.SearchBrains{Object: objectName})
+	return results, err
+}
+
+// BestAesthetic returns photos with the highest aesthetic scores. It's a
+// thin wrapper around Find, kept for backward compatibility.
+func (s *BrainsSearch) BestAesthetic(limit int) (results PhotoResults, err error) {
+	if limit <= 0 {
+		limit = 20
+	} else if limit > brainsSearchMaxCount {
+		limit = brainsSearchMaxCount
+	}
+
+	results, _, err = s.Find(form.SearchBrains{
+		AestheticMin: 0.01, // exclude unscored photos, mirroring the old "> 0" check
+		Order:        "aesthetic",
+		Count:        limit,
+	})
+
+	return results, err
 }
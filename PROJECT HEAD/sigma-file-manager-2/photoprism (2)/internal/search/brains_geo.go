@@ -0,0 +1,88 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/photoprism/photoprism/internal/entity"
+	"github.com/photoprism/photoprism/internal/form"
+	"github.com/photoprism/photoprism/pkg/txt"
+)
+
+// brainsGeoNearDegrees bounds a Near search to roughly a 10 km square
+// around the reference photo's coordinates, since a precise great-circle
+// radius isn't worth the complexity for a map viewport search.
+const brainsGeoNearDegrees = 0.05
+
+// BrainsGeo returns photos matching f's BRAINS predicates within the
+// requested map viewport, S2 cell, or area around an existing photo, in
+// the same GeoResults shape the existing photo geo search returns, so the
+// map view can render and cluster BRAINS-filtered results ("outdoor sunset
+// photos with aesthetic>6 in this viewport") the same way it already
+// clusters ordinary geo search results by CellID prefix/S2 level.
+func (s *BrainsSearch) BrainsGeo(f form.SearchBrainsGeo) (results entity.GeoResults, err error) {
+	q := s.Query.db.Table("photos").
+		Select("photos.photo_uid, photos.photo_lat, photos.photo_lng, photos.photo_title, photos.taken_at, photos.cell_id").
+		Joins("JOIN brains_results ON brains_results.photo_id = photos.id").
+		Where("photos.photo_lat <> 0")
+
+	switch {
+	case f.S2 != "":
+		q = q.Where("photos.cell_id LIKE ?", strings.TrimSpace(f.S2)+"%")
+	case f.Near != "":
+		if q, err = s.applyNear(q, f.Near); err != nil {
+			return results, err
+		}
+	case f.North != 0 && f.South != 0 && f.East != 0 && f.West != 0:
+		q = q.Where("photos.photo_lat BETWEEN ? AND ? AND photos.photo_lng BETWEEN ? AND ?", f.South, f.North, f.West, f.East)
+	}
+
+	if f.SceneType != "" {
+		q = q.Where("LOWER(brains_results.scene_type) = ?", txt.Lower(strings.TrimSpace(f.SceneType)))
+	}
+
+	if f.Weather != "" {
+		q = q.Where("LOWER(brains_results.weather) = ?", txt.Lower(strings.TrimSpace(f.Weather)))
+	}
+
+	if f.Object != "" {
+		q = q.Where("brains_results.object_results LIKE ?", "%\"label\":\""+txt.Lower(strings.TrimSpace(f.Object))+"\"%")
+	}
+
+	if f.AestheticMin > 0 {
+		q = q.Where("brains_results.aesthetic_score >= ?", f.AestheticMin)
+	}
+
+	if err = s.Query.Filters(q); err != nil {
+		return results, err
+	}
+
+	if err = q.Scan(&results).Error; err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+// applyNear resolves photoUID's coordinates and constrains q to the
+// brainsGeoNearDegrees box around them.
+func (s *BrainsSearch) applyNear(q *gorm.DB, photoUID string) (*gorm.DB, error) {
+	var center struct {
+		PhotoLat float32
+		PhotoLng float32
+	}
+
+	near := s.Query.db.Table("photos").
+		Select("photo_lat, photo_lng").
+		Where("photos.photo_uid = ?", photoUID)
+
+	if err := near.Scan(&center).Error; err != nil {
+		return q, fmt.Errorf("brains: failed to resolve near photo %s: %v", photoUID, err)
+	}
+
+	return q.Where("photos.photo_lat BETWEEN ? AND ? AND photos.photo_lng BETWEEN ? AND ?",
+		center.PhotoLat-brainsGeoNearDegrees, center.PhotoLat+brainsGeoNearDegrees,
+		center.PhotoLng-brainsGeoNearDegrees, center.PhotoLng+brainsGeoNearDegrees), nil
+}
@@ -0,0 +1,543 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/photoprism/photoprism/pkg/txt"
+)
+
+// brainsQueryTerm is one parsed term of a BrainsSearch.Query string: either
+// a bareword, matched against scene type, detected objects and keywords, or
+// a typed predicate ("scene:", "weather:", "time:", "indoor:", "aesthetic:",
+// "object:"), optionally negated with a leading "-".
+type brainsQueryTerm struct {
+	Kind   string  // "bareword", "scene", "weather", "time", "indoor", "aesthetic", "object"
+	Value  string  // lowercased scene/weather/time/indoor/object value
+	Op     string  // aesthetic comparison operator: ">", ">=", "<", "<="
+	Number float32 // aesthetic threshold, or object minimum confidence
+	Negate bool
+}
+
+// parseBrainsQuery splits q into OR-separated groups of AND-ed terms, using
+// the same whitespace tokenization the rest of this codebase's search terms
+// use. A bare "AND" token is consumed as the (default) term separator; "OR"
+// starts a new group.
+func parseBrainsQuery(q string) (groups [][]brainsQueryTerm) {
+	var group []brainsQueryTerm
+
+	for _, token := range strings.Fields(q) {
+		switch token {
+		case "AND":
+			continue
+		case "OR":
+			if len(group) > 0 {
+				groups = append(groups, group)
+				group = nil
+			}
+			continue
+		}
+
+		group = append(group, parseBrainsQueryTerm(token))
+	}
+
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// parseBrainsQueryTerm parses a single token into a term.
+func parseBrainsQueryTerm(token string) brainsQueryTerm {
+	term := brainsQueryTerm{Kind: "bareword"}
+
+	if strings.HasPrefix(token, "-") && len(token) > 1 {
+		term.Negate = true
+		token = token[1:]
+	}
+
+	key, value, hasKey := cutString(token, ":")
+
+	if !hasKey {
+		term.Value = txt.Lower(token)
+		return term
+	}
+
+	switch txt.Lower(key) {
+	case "scene":
+		term.Kind, term.Value = "scene", txt.Lower(value)
+	case "weather":
+		term.Kind, term.Value = "weather", txt.Lower(value)
+	case "time":
+		term.Kind, term.Value = "time", txt.Lower(value)
+	case "indoor":
+		term.Kind = "indoor"
+		if txt.Lower(value) == "false" {
+			term.Value = "outdoor"
+		} else {
+			term.Value = "indoor"
+		}
+	case "aesthetic":
+		term.Kind = "aesthetic"
+		term.Op, term.Number = parseAestheticTerm(value)
+	case "object":
+		term.Kind = "object"
+		label, confidence, hasConfidence := cutString(value, "@")
+		term.Value = txt.Lower(label)
+		if hasConfidence {
+			if f, err := strconv.ParseFloat(confidence, 32); err == nil {
+				term.Number = float32(f)
+			}
+		}
+	default:
+		// An unrecognized predicate key falls back to a bareword match on
+		// the whole token, so a typo doesn't silently drop the term.
+		term.Kind, term.Value = "bareword", txt.Lower(key+":"+value)
+	}
+
+	return term
+}
+
+// cutString splits s on the first occurrence of sep, reporting whether sep
+// was found, mirroring strings.Cut for codebases predating its addition.
+func cutString(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
+
+// parseAestheticTerm parses a value like ">7", "<=3", or "7" into its
+// comparison operator and numeric threshold. A bare number defaults to
+// ">=", so "aesthetic:7" behaves like "at least 7".
+func parseAestheticTerm(value string) (op string, number float32) {
+	for _, candidate := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(value, candidate) {
+			n, _ := strconv.ParseFloat(strings.TrimPrefix(value, candidate), 32)
+			return candidate, float32(n)
+		}
+	}
+
+	n, _ := strconv.ParseFloat(value, 32)
+
+	return ">=", float32(n)
+}
+
+// brainsPredicate is one node of the tree Query compiles a parsed query
+// into. Each node resolves to the set of distinct photo IDs it matches,
+// rather than a SQL clause: brains_objects and brains_keywords are
+// one-row-per-label/keyword tables, so ANDing two column comparisons
+// against a single joined row can never require two different labels to
+// both be present on the same photo. Resolving each leaf to its own
+// photo-ID query (the same pattern objectPhotoIDs/keywordPhotoIDs use) and
+// combining the resulting sets in Go is what makes multi-term AND queries
+// match correctly instead of silently dropping photos.
+type brainsPredicate interface {
+	PhotoIDs(s *BrainsSearch) ([]string, error)
+}
+
+// brainsGroup is one AND-ed group of terms from a single OR-separated
+// query segment. Positive terms are intersected; Negated terms are then
+// subtracted from that intersection, or from every photo with a BRAINS
+// result if the group has no positive term, since excluding photos from a
+// set needs a universe to subtract from, unlike a bare SQL "NOT (...)".
+type brainsGroup struct {
+	Positive []brainsPredicate
+	Negated  []brainsPredicate
+}
+
+// PhotoIDs implements brainsPredicate.
+func (g brainsGroup) PhotoIDs(s *BrainsSearch) ([]string, error) {
+	var ids []string
+	matched := false
+
+	for _, p := range g.Positive {
+		next, err := p.PhotoIDs(s)
+		if err != nil {
+			return nil, err
+		}
+
+		if !matched {
+			ids, matched = next, true
+			continue
+		}
+
+		ids = intersectBrainsIDs(ids, next)
+	}
+
+	if !matched {
+		all, err := s.brainsResultIDs("1 = 1")
+		if err != nil {
+			return nil, err
+		}
+
+		ids = all
+	}
+
+	for _, p := range g.Negated {
+		excluded, err := p.PhotoIDs(s)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = subtractBrainsIDs(ids, excluded)
+	}
+
+	return ids, nil
+}
+
+// brainsOr requires at least one child predicate to match, so it resolves
+// to the union of each child's photo IDs.
+type brainsOr struct {
+	Children []brainsPredicate
+}
+
+// PhotoIDs implements brainsPredicate.
+func (p brainsOr) PhotoIDs(s *BrainsSearch) ([]string, error) {
+	seen := make(map[string]bool)
+	var ids []string
+
+	for _, child := range p.Children {
+		matched, err := child.PhotoIDs(s)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range matched {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+// intersectBrainsIDs returns the IDs present in both a and b.
+func intersectBrainsIDs(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, id := range b {
+		inB[id] = true
+	}
+
+	var ids []string
+	for _, id := range a {
+		if inB[id] {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// subtractBrainsIDs returns the IDs in a that aren't also in b.
+func subtractBrainsIDs(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, id := range b {
+		inB[id] = true
+	}
+
+	var ids []string
+	for _, id := range a {
+		if !inB[id] {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// brainsResultIDs returns the IDs of photos whose brains_results row
+// matches where/args, the single-row-per-photo query every
+// brains_results-backed leaf predicate below shares.
+func (s *BrainsSearch) brainsResultIDs(where string, args ...interface{}) (ids []string, err error) {
+	q := s.Query.db.Table("brains_results").Where(where, args...)
+
+	if err = q.Pluck("DISTINCT photo_id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// brainsSceneIs matches a single scene type.
+type brainsSceneIs struct{ Value string }
+
+func (p brainsSceneIs) PhotoIDs(s *BrainsSearch) ([]string, error) {
+	return s.brainsResultIDs("LOWER(scene_type) = ?", p.Value)
+}
+
+// brainsWeatherIs matches a single weather condition.
+type brainsWeatherIs struct{ Value string }
+
+func (p brainsWeatherIs) PhotoIDs(s *BrainsSearch) ([]string, error) {
+	return s.brainsResultIDs("LOWER(weather) = ?", p.Value)
+}
+
+// brainsTimeIs matches a single time of day.
+type brainsTimeIs struct{ Value string }
+
+func (p brainsTimeIs) PhotoIDs(s *BrainsSearch) ([]string, error) {
+	return s.brainsResultIDs("LOWER(time_of_day) = ?", p.Value)
+}
+
+// brainsIndoorOutdoorIs matches a single indoor/outdoor setting.
+type brainsIndoorOutdoorIs struct{ Value string }
+
+func (p brainsIndoorOutdoorIs) PhotoIDs(s *BrainsSearch) ([]string, error) {
+	return s.brainsResultIDs("LOWER(indoor_outdoor) = ?", p.Value)
+}
+
+// brainsAestheticCompare matches photos whose aesthetic score satisfies Op Number.
+type brainsAestheticCompare struct {
+	Op     string
+	Number float32
+}
+
+func (p brainsAestheticCompare) PhotoIDs(s *BrainsSearch) ([]string, error) {
+	return s.brainsResultIDs(fmt.Sprintf("aesthetic_score %s ?", p.Op), p.Number)
+}
+
+// brainsObjectLabel matches photos with a detected object label, optionally
+// requiring at least MinConfidence. It delegates to objectPhotoIDs so a
+// label resolves to its own photo-ID set rather than a join condition,
+// which is what lets two brainsObjectLabel leaves be ANDed together
+// correctly.
+type brainsObjectLabel struct {
+	Label         string
+	MinConfidence float32
+}
+
+func (p brainsObjectLabel) PhotoIDs(s *BrainsSearch) ([]string, error) {
+	return s.objectPhotoIDs(p.Label, p.MinConfidence)
+}
+
+// brainsBareword matches a plain word against scene type, detected objects,
+// and keyword prefixes, since a free-text query doesn't say which of the
+// three the user meant. Each is resolved to its own photo-ID set and
+// unioned, for the same reason brainsObjectLabel delegates to
+// objectPhotoIDs instead of comparing columns on a joined row.
+type brainsBareword struct{ Value string }
+
+func (p brainsBareword) PhotoIDs(s *BrainsSearch) ([]string, error) {
+	sceneIDs, err := s.brainsResultIDs("LOWER(scene_type) = ?", p.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	objectIDs, err := s.objectPhotoIDs(p.Value, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	keywordIDs, err := s.keywordPhotoIDs(p.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+
+	for _, set := range [][]string{sceneIDs, objectIDs, keywordIDs} {
+		for _, id := range set {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+// compileBrainsQuery turns parsed OR-groups of AND-ed terms into a single
+// brainsPredicate tree, one brainsGroup per OR-separated segment.
+func compileBrainsQuery(groups [][]brainsQueryTerm) brainsPredicate {
+	var orChildren []brainsPredicate
+
+	for _, group := range groups {
+		var g brainsGroup
+
+		for _, term := range group {
+			pred := brainsTermPredicate(term)
+
+			if term.Negate {
+				g.Negated = append(g.Negated, pred)
+			} else {
+				g.Positive = append(g.Positive, pred)
+			}
+		}
+
+		orChildren = append(orChildren, g)
+	}
+
+	if len(orChildren) == 1 {
+		return orChildren[0]
+	}
+
+	return brainsOr{Children: orChildren}
+}
+
+// brainsTermPredicate converts a single parsed term into its leaf predicate.
+func brainsTermPredicate(term brainsQueryTerm) brainsPredicate {
+	switch term.Kind {
+	case "scene":
+		return brainsSceneIs{Value: term.Value}
+	case "weather":
+		return brainsWeatherIs{Value: term.Value}
+	case "time":
+		return brainsTimeIs{Value: term.Value}
+	case "indoor":
+		return brainsIndoorOutdoorIs{Value: term.Value}
+	case "aesthetic":
+		return brainsAestheticCompare{Op: term.Op, Number: term.Number}
+	case "object":
+		return brainsObjectLabel{Label: term.Value, MinConfidence: term.Number}
+	default:
+		return brainsBareword{Value: term.Value}
+	}
+}
+
+// Query parses q using a small grammar (bareword terms matched against
+// scene type/detected objects/keywords; typed predicates "scene:",
+// "weather:", "time:", "indoor:", "aesthetic:>7", "object:dog@0.8"; "AND"
+// as the default separator, "OR" to start an alternative, and a leading
+// "-" to negate a term), resolves it to the set of matching photo IDs, and
+// returns those photos ordered by a weighted relevance score: summed
+// object confidence, keyword match count, and an aesthetic bonus.
+func (s *BrainsSearch) Query(q string) (results PhotoResults, count int, err error) {
+	groups := parseBrainsQuery(q)
+
+	if len(groups) == 0 {
+		return PhotoResults{}, 0, nil
+	}
+
+	ids, err := compileBrainsQuery(groups).PhotoIDs(s)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(ids) == 0 {
+		return PhotoResults{}, 0, nil
+	}
+
+	scores, err := s.brainsQueryScores(ids, groups)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	photosQuery := s.Query.db.Table("photos").Select("photos.*").Where("photos.id IN (?)", ids)
+
+	if err = s.Query.Filters(photosQuery); err != nil {
+		return nil, 0, err
+	}
+
+	if err = photosQuery.Scan(&results).Error; err != nil {
+		return nil, 0, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return scores[results[i].ID] > scores[results[j].ID]
+	})
+
+	return results, len(results), nil
+}
+
+// brainsQueryScores computes a weighted relevance score for each of ids:
+// the sum of matched object confidences, the count of matched keywords,
+// and an aesthetic bonus (aesthetic_score / 10), so Query can rank results
+// instead of just filtering them.
+func (s *BrainsSearch) brainsQueryScores(ids []string, groups [][]brainsQueryTerm) (map[string]float32, error) {
+	scores := make(map[string]float32, len(ids))
+
+	for _, id := range ids {
+		scores[id] = 0
+	}
+
+	var labels, keywords []string
+
+	for _, group := range groups {
+		for _, term := range group {
+			if term.Negate {
+				continue
+			}
+
+			switch term.Kind {
+			case "object":
+				labels = append(labels, term.Value)
+			case "bareword":
+				labels = append(labels, term.Value)
+				keywords = append(keywords, term.Value)
+			}
+		}
+	}
+
+	if len(labels) > 0 {
+		var objectScores []struct {
+			PhotoID    string
+			Confidence float32
+		}
+
+		if err := s.Query.db.Table("brains_objects").
+			Select("photo_id, confidence").
+			Where("photo_id IN (?) AND label IN (?)", ids, labels).
+			Scan(&objectScores).Error; err != nil {
+			return nil, err
+		}
+
+		for _, o := range objectScores {
+			scores[o.PhotoID] += o.Confidence
+		}
+	}
+
+	if len(keywords) > 0 {
+		likeClauses := make([]string, len(keywords))
+		likeArgs := make([]interface{}, 0, len(keywords)+1)
+		likeArgs = append(likeArgs, ids)
+
+		for i, keyword := range keywords {
+			likeClauses[i] = "keyword LIKE ?"
+			likeArgs = append(likeArgs, keyword+"%")
+		}
+
+		var keywordCounts []struct {
+			PhotoID string
+			Count   int
+		}
+
+		if err := s.Query.db.Table("brains_keywords").
+			Select("photo_id, COUNT(*) AS count").
+			Where("photo_id IN (?) AND ("+strings.Join(likeClauses, " OR ")+")", likeArgs...).
+			Group("photo_id").
+			Scan(&keywordCounts).Error; err != nil {
+			return nil, err
+		}
+
+		for _, k := range keywordCounts {
+			scores[k.PhotoID] += float32(k.Count)
+		}
+	}
+
+	var aesthetics []struct {
+		PhotoID        string
+		AestheticScore float32
+	}
+
+	if err := s.Query.db.Table("brains_results").
+		Select("photo_id, aesthetic_score").
+		Where("photo_id IN (?)", ids).
+		Scan(&aesthetics).Error; err != nil {
+		return nil, err
+	}
+
+	for _, a := range aesthetics {
+		scores[a.PhotoID] += a.AestheticScore / 10
+	}
+
+	return scores, nil
+}
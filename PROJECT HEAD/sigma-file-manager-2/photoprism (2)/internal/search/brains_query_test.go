@@ -0,0 +1,101 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/photoprism/photoprism/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestBrainsSearch returns a BrainsSearch backed by the test database, so
+// Query/PhotoIDs tests can exercise real SQL instead of mocking gorm.
+func newTestBrainsSearch(t *testing.T) *BrainsSearch {
+	entity.SetupTestDB(t)
+
+	return NewBrainsSearch(&Query{db: entity.Db()})
+}
+
+// createTestBrainsPhoto inserts a photo with the given detected object
+// labels, so multi-term query tests can set up photos that share some
+// labels and differ on others.
+func createTestBrainsPhoto(t *testing.T, uid string, labels ...string) {
+	photo := entity.NewPhoto(false)
+	photo.PhotoUID = uid
+	assert.NoError(t, photo.Create())
+
+	for _, label := range labels {
+		row := entity.BrainsObject{PhotoID: photo.ID, Label: label, Confidence: 0.9}
+		assert.NoError(t, entity.Db().Create(&row).Error)
+	}
+}
+
+// TestCompileBrainsQuery_MultiTermAndRequiresEveryLabel verifies that an
+// implicit-AND query like "dog cat" only matches photos that have both
+// labels, not photos that merely have one of the two on a separate
+// brains_objects row (the bug a single joined WHERE clause can't avoid).
+func TestCompileBrainsQuery_MultiTermAndRequiresEveryLabel(t *testing.T) {
+	s := newTestBrainsSearch(t)
+
+	createTestBrainsPhoto(t, "pxbqt0000000001", "dog", "cat")
+	createTestBrainsPhoto(t, "pxbqt0000000002", "dog")
+
+	groups := parseBrainsQuery("dog cat")
+	ids, err := compileBrainsQuery(groups).PhotoIDs(s)
+
+	assert.NoError(t, err)
+	assert.Len(t, ids, 1)
+}
+
+// TestCompileBrainsQuery_OrMatchesEitherLabel verifies "dog OR cat" matches
+// a photo that has only one of the two labels.
+func TestCompileBrainsQuery_OrMatchesEitherLabel(t *testing.T) {
+	s := newTestBrainsSearch(t)
+
+	createTestBrainsPhoto(t, "pxbqt0000000003", "dog")
+	createTestBrainsPhoto(t, "pxbqt0000000004", "cat")
+	createTestBrainsPhoto(t, "pxbqt0000000005", "bird")
+
+	groups := parseBrainsQuery("dog OR cat")
+	ids, err := compileBrainsQuery(groups).PhotoIDs(s)
+
+	assert.NoError(t, err)
+	assert.Len(t, ids, 2)
+}
+
+// TestCompileBrainsQuery_NegatedTermExcludesMatches verifies "dog -cat"
+// matches a photo with "dog" but not one that also has "cat".
+func TestCompileBrainsQuery_NegatedTermExcludesMatches(t *testing.T) {
+	s := newTestBrainsSearch(t)
+
+	createTestBrainsPhoto(t, "pxbqt0000000006", "dog")
+	createTestBrainsPhoto(t, "pxbqt0000000007", "dog", "cat")
+
+	groups := parseBrainsQuery("dog -cat")
+	ids, err := compileBrainsQuery(groups).PhotoIDs(s)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pxbqt0000000006"}, idsByPhotoUID(t, ids))
+}
+
+// idsByPhotoUID maps photo.ID values back to their PhotoUID, so assertions
+// can compare against the readable UIDs createTestBrainsPhoto was given.
+func idsByPhotoUID(t *testing.T, ids []string) []string {
+	uids := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		var photo entity.Photo
+		assert.NoError(t, entity.Db().Where("id = ?", id).First(&photo).Error)
+		uids = append(uids, photo.PhotoUID)
+	}
+
+	return uids
+}
+
+func TestIntersectBrainsIDs(t *testing.T) {
+	assert.ElementsMatch(t, []string{"b", "c"}, intersectBrainsIDs([]string{"a", "b", "c"}, []string{"b", "c", "d"}))
+	assert.Empty(t, intersectBrainsIDs([]string{"a"}, []string{"b"}))
+}
+
+func TestSubtractBrainsIDs(t *testing.T) {
+	assert.Equal(t, []string{"a"}, subtractBrainsIDs([]string{"a", "b"}, []string{"b", "c"}))
+}
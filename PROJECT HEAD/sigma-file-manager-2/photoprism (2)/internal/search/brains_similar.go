@@ -0,0 +1,149 @@
+package search
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// brainsSimilarMinScore is the minimum cosine similarity Similar keeps a
+// candidate at, so a reference photo's embedding doesn't pull in the whole
+// library as "similar".
+const brainsSimilarMinScore = 0.75
+
+// brainsSimilarDefaultLimit is the result count Similar falls back to when
+// limit is not positive.
+const brainsSimilarDefaultLimit = 20
+
+// decodeBrainsEmbedding parses a BrainsResult.Embedding BLOB (little-endian
+// float32s, as produced by brains.EncodeEmbedding) into a vector.
+func decodeBrainsEmbedding(raw string) ([]float32, error) {
+	if len(raw)%4 != 0 {
+		return nil, fmt.Errorf("search: malformed embedding, length %d is not a multiple of 4", len(raw))
+	}
+
+	vec := make([]float32, len(raw)/4)
+	buf := []byte(raw)
+
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+
+	return vec, nil
+}
+
+// cosineSimilarity returns the cosine similarity of a and b, or 0 if either
+// is a zero vector or they differ in length.
+func cosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
+
+// Similar returns up to limit photos visually/semantically close to
+// photoUID's BRAINS embedding, scoring every other indexed embedding by
+// exact cosine similarity, keeping only matches at or above
+// brainsSimilarMinScore, and combining the result with the standard
+// Query.Filters so callers can ask for "photos similar to X taken in 2023
+// in album Y". This is the brute-force backend; brains.EmbeddingIndex
+// offers an LSH-backed approximate alternative for the live service.
+func (s *BrainsSearch) Similar(photoUID string, limit int) (results PhotoResults, err error) {
+	if photoUID == "" {
+		return results, fmt.Errorf("photo UID is missing")
+	}
+
+	if limit <= 0 {
+		limit = brainsSimilarDefaultLimit
+	}
+
+	var origin struct {
+		PhotoID   string
+		Embedding string
+	}
+
+	if err = s.Query.db.Table("brains_results").
+		Select("brains_results.photo_id, brains_results.embedding").
+		Joins("JOIN photos ON photos.id = brains_results.photo_id").
+		Where("photos.photo_uid = ?", photoUID).
+		Scan(&origin).Error; err != nil {
+		return results, err
+	}
+
+	if origin.Embedding == "" {
+		return results, fmt.Errorf("photo has no BRAINS embedding")
+	}
+
+	originVec, err := decodeBrainsEmbedding(origin.Embedding)
+	if err != nil {
+		return results, err
+	}
+
+	var candidates []struct {
+		PhotoID   string
+		Embedding string
+	}
+
+	if err = s.Query.db.Table("brains_results").
+		Select("photo_id, embedding").
+		Where("embedding != '' AND photo_id != ?", origin.PhotoID).
+		Scan(&candidates).Error; err != nil {
+		return results, err
+	}
+
+	scores := make(map[string]float32, len(candidates))
+	var ids []string
+
+	for _, candidate := range candidates {
+		vec, decodeErr := decodeBrainsEmbedding(candidate.Embedding)
+		if decodeErr != nil {
+			continue
+		}
+
+		score := cosineSimilarity(originVec, vec)
+		if score < brainsSimilarMinScore {
+			continue
+		}
+
+		scores[candidate.PhotoID] = score
+		ids = append(ids, candidate.PhotoID)
+	}
+
+	if len(ids) == 0 {
+		return PhotoResults{}, nil
+	}
+
+	q := s.Query.db.Table("photos").Select("photos.*").Where("photos.id IN (?)", ids)
+
+	if err = s.Query.Filters(q); err != nil {
+		return results, err
+	}
+
+	if err = q.Scan(&results).Error; err != nil {
+		return results, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return scores[results[i].ID] > scores[results[j].ID]
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}